@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kirtansoni/words-weave/internal/associations"
+	database "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/generate"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	"github.com/kirtansoni/words-weave/internal/quality"
+)
+
+// generateCommand runs `generate` to write starting passages for the new
+// quotes of the database, accepted ones become drafts. It returns the exit
+// code.
+func generateCommand(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "quotes to write passages for, 0 for all")
+	provider := fs.String("provider", "openai", "passages from openai or the offline fake")
+	stemmer := fs.String("stemmer", "remote", "local suffix stripping or the remote microservice")
+	seed := fs.Uint64("seed", 1, "seed of the topic picks and the fake")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words-weave [-db file] generate [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	g := &generate.Generator{Seed: *seed}
+	switch *provider {
+	case "openai":
+		g.LLM = l.GetProvider()
+	case "fake":
+		g.LLM = l.Fake{Seed: *seed}
+	default:
+		fs.Usage()
+		return 2
+	}
+	switch *stemmer {
+	case "local":
+		g.Validator.Stemmer = matcher.LocalStemmer{}
+	case "remote":
+		g.Validator.Stemmer = matcher.GetStemmer()
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	db, err := database.Open(*dbfile, *automigrate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Database failed:", err)
+		return 1
+	}
+	defer db.Close()
+	g.DB = db
+	g.Validator.Graph = associations.New(db)
+
+	results, err := g.Run(context.Background(), *limit)
+	accepted := 0
+	for _, result := range results {
+		status := "rejected"
+		if result.Accepted {
+			status = "draft"
+			accepted++
+		}
+		fmt.Printf("quote %-5d %-8s %d tries  %s\n", result.Quote, status, result.Tries, result.Topic)
+		for _, check := range result.Report.Checks {
+			if check.Status != quality.PASS {
+				fmt.Printf("      %-4s  %s: %s\n", check.Status, check.Name, check.Detail)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Generating failed:", err)
+		return 1
+	}
+	fmt.Printf("%d of %d quotes are drafts now\n", accepted, len(results))
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	database "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/importer"
+)
+
+// importCommand runs `import file...` to add quote collections to the
+// database as new quotes, it returns the exit code.
+func importCommand(args []string) int {
+	im := importer.New()
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "csv, json or zenquotes, guessed from the file when empty")
+	fs.StringVar(&im.Source, "source", "", "source of quotes that don't name one")
+	fs.StringVar(&im.License, "license", "", "license of quotes that don't name one")
+	fs.StringVar(&im.Language, "language", "", "language of quotes that don't name one")
+	fs.IntVar(&im.MinWords, "min-words", importer.MIN_WORDS, "shortest quote kept, in words")
+	fs.IntVar(&im.MaxWords, "max-words", importer.MAX_WORDS, "longest quote kept, in words")
+	verbose := fs.Bool("v", false, "list every rejected quote")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words-weave [-db file] import [flags] file...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	db, err := database.Open(*dbfile, *automigrate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Database failed:", err)
+		return 1
+	}
+	defer db.Close()
+
+	for _, path := range fs.Args() {
+		result, err := im.ImportFile(db, path, *format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Import failed:", err)
+			return 1
+		}
+		reasons := make(map[string]int)
+		for _, rejection := range result.Rejected {
+			reasons[rejection.Reason]++
+			if *verbose {
+				fmt.Printf("  %s #%d %s: %q\n", path, rejection.Record, rejection.Reason, rejection.Quote)
+			}
+		}
+		fmt.Printf("%s: imported %d, rejected %d %v\n", path, result.Imported, len(result.Rejected), reasons)
+	}
+	return 0
+}
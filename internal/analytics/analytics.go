@@ -0,0 +1,234 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// error classes of attempts that didn't go through
+const (
+	ERROR_TIMEOUT      = "timeout"
+	ERROR_DISCONNECTED = "disconnected"
+	ERROR_LLM          = "llm"
+	ERROR_MATCHER      = "matcher"
+)
+
+// Event is one attempt at a challenge, successful or not, or a hint taken.
+type Event struct {
+	Session   string
+	Day       string
+	Practice  bool
+	Language  string
+	Challenge int
+	Attempt   int
+	Parent    int
+	Input     []string
+	Output    string
+	// challenge words this attempt found first
+	Matched       []int
+	Latency       time.Duration
+	Model         string
+	PromptVersion string
+	// passages aren't cached yet, so this is always false
+	CacheHit   bool
+	ErrorClass string
+	// tier of a hint, empty for attempts. Hints generate nothing, a reveal
+	// matches the word it reveals.
+	Hint string
+	Time time.Time
+}
+
+// Classify names the kind of error an attempt failed with.
+func Classify(err error, matching bool) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return ERROR_TIMEOUT
+	case errors.Is(err, context.Canceled):
+		return ERROR_DISCONNECTED
+	case matching:
+		return ERROR_MATCHER
+	}
+	return ERROR_LLM
+}
+
+// Log appends attempt events and answers questions about them. Events are
+// never changed once written, the table refuses updates and deletes.
+type Log struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Log {
+	return &Log{db: db}
+}
+
+func (l *Log) Record(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	// stored as text, UTC keeps it sortable
+	e.Time = e.Time.UTC()
+	input, err := json.Marshal(nonNil(e.Input))
+	if err != nil {
+		return err
+	}
+	matched, err := json.Marshal(nonNil(e.Matched))
+	if err != nil {
+		return err
+	}
+	_, err = l.db.Exec(`INSERT INTO attempt_events (session, day, practice, language, challenge, attempt, parent,
+			input_words, output, matched, latency_ms, model, prompt_version, cache_hit, error_class, hint, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Session, e.Day, e.Practice, e.Language, e.Challenge, e.Attempt, e.Parent,
+		string(input), e.Output, string(matched), e.Latency.Milliseconds(), e.Model, e.PromptVersion, e.CacheHit, e.ErrorClass, e.Hint, e.Time)
+	return err
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// WordCount is how often a word was picked.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Play is what the log says about one session's play of a challenge.
+type Play struct {
+	// the last attempt made, hints included
+	Last int
+	// challenge word to the first attempt that found it
+	Found map[int]int
+}
+
+// Plays are the daily plays of a challenge by session, practice replays are
+// left out. Words revealed by a hint count as found by it.
+func (l *Log) Plays(day, language string, challenge int) (map[string]*Play, error) {
+	rows, err := l.db.Query(`SELECT session, attempt, matched FROM attempt_events
+		WHERE day = ? AND language = ? AND challenge = ? AND NOT practice AND error_class = ''`,
+		day, language, challenge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plays := make(map[string]*Play)
+	for rows.Next() {
+		var session, matchedJSON string
+		var attempt int
+		if err := rows.Scan(&session, &attempt, &matchedJSON); err != nil {
+			return nil, err
+		}
+		var matched []int
+		if err := json.Unmarshal([]byte(matchedJSON), &matched); err != nil {
+			return nil, err
+		}
+		p, ok := plays[session]
+		if !ok {
+			p = &Play{Found: make(map[int]int)}
+			plays[session] = p
+		}
+		p.Last = max(p.Last, attempt)
+		for _, target := range matched {
+			if at, ok := p.Found[target]; !ok || attempt < at {
+				p.Found[target] = attempt
+			}
+		}
+	}
+	return plays, rows.Err()
+}
+
+// MedianAttempts is, for each challenge word, the median attempt that found
+// it among the daily players who found it. Practice replays are left out.
+func (l *Log) MedianAttempts(day, language string, challenge int) (map[int]float64, error) {
+	plays, err := l.Plays(day, language, challenge)
+	if err != nil {
+		return nil, err
+	}
+	byTarget := make(map[int][]int)
+	for _, p := range plays {
+		for target, attempt := range p.Found {
+			byTarget[target] = append(byTarget[target], attempt)
+		}
+	}
+	medians := make(map[int]float64, len(byTarget))
+	for target, attempts := range byTarget {
+		medians[target] = Median(attempts)
+	}
+	return medians, nil
+}
+
+// Median of values, 0 when there are none.
+func Median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return float64(values[n/2])
+	}
+	return float64(values[n/2-1]+values[n/2]) / 2
+}
+
+// StartingWords are the words most often picked for the first attempt at a
+// challenge since a time, most popular first.
+func (l *Log) StartingWords(language string, since time.Time, limit int) ([]WordCount, error) {
+	rows, err := l.db.Query(`SELECT word.value, COUNT(*) AS n
+		FROM attempt_events e, json_each(e.input_words) word
+		WHERE e.attempt = 1 AND e.language = ? AND e.created_at >= ? AND e.error_class = '' AND e.hint = ''
+		GROUP BY word.value ORDER BY n DESC, word.value LIMIT ?`, language, since.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := []WordCount{}
+	for rows.Next() {
+		var c WordCount
+		if err := rows.Scan(&c.Word, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// History is every attempt a session made, in order.
+func (l *Log) History(session string) ([]Event, error) {
+	rows, err := l.db.Query(`SELECT session, day, practice, language, challenge, attempt, parent, input_words, output,
+			matched, latency_ms, model, prompt_version, cache_hit, error_class, hint, created_at
+		FROM attempt_events WHERE session = ? ORDER BY id`, session)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		var input, matched string
+		var latency int64
+		err := rows.Scan(&e.Session, &e.Day, &e.Practice, &e.Language, &e.Challenge, &e.Attempt, &e.Parent, &input, &e.Output,
+			&matched, &latency, &e.Model, &e.PromptVersion, &e.CacheHit, &e.ErrorClass, &e.Hint, &e.Time)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(input), &e.Input); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(matched), &e.Matched); err != nil {
+			return nil, err
+		}
+		e.Latency = time.Duration(latency) * time.Millisecond
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
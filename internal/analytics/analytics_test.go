@@ -0,0 +1,93 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+)
+
+func TestQueries(t *testing.T) {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	l := New(db)
+	now := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{Session: "a", Attempt: 1, Input: []string{"dream", "sky"}, Matched: []int{0}},
+		{Session: "a", Attempt: 2, Input: []string{"light"}, Matched: []int{2}},
+		{Session: "b", Attempt: 1, Input: []string{"dream"}},
+		{Session: "b", Attempt: 2, Input: []string{"night"}, ErrorClass: ERROR_LLM},
+		{Session: "b", Attempt: 2, Input: []string{"night"}, Matched: []int{0, 2}},
+		{Session: "c", Attempt: 1, Input: []string{"sky"}, Matched: []int{2}},
+		{Session: "c", Attempt: 4, Input: []string{"hope"}, Matched: []int{0}},
+		// a hint taken before any attempt
+		{Session: "e", Attempt: 1, Hint: "letter"},
+		// practice doesn't count
+		{Session: "d@2026-03-01", Practice: true, Attempt: 1, Input: []string{"moon"}, Matched: []int{0, 2}},
+	}
+	for _, e := range events {
+		e.Day, e.Language, e.Time = "2026-03-04", "en", now
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	medians, err := l.MedianAttempts("2026-03-04", "en", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]float64{0: 2, 2: 2}; !reflect.DeepEqual(medians, want) {
+		t.Errorf("medians %v, want %v", medians, want)
+	}
+
+	words, err := l.StartingWords("en", now.Add(-time.Hour), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []WordCount{{"dream", 2}, {"sky", 2}}; !reflect.DeepEqual(words, want) {
+		t.Errorf("starting words %v, want %v", words, want)
+	}
+
+	history, err := l.History("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[1].ErrorClass != ERROR_LLM || !reflect.DeepEqual(history[2].Matched, []int{0, 2}) ||
+		!history[2].Time.Equal(now) {
+		t.Errorf("history %+v", history)
+	}
+
+	if history, _ := l.History("e"); len(history) != 1 || history[0].Hint != "letter" {
+		t.Errorf("hint history %+v", history)
+	}
+
+	if _, err := db.Exec(`DELETE FROM attempt_events`); err == nil {
+		t.Error("events were deleted")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err      error
+		matching bool
+		class    string
+	}{
+		{nil, false, ""},
+		{fmt.Errorf("stream: %w", context.DeadlineExceeded), false, ERROR_TIMEOUT},
+		{context.Canceled, false, ERROR_DISCONNECTED},
+		{fmt.Errorf("stemmer down"), true, ERROR_MATCHER},
+		{fmt.Errorf("503"), false, ERROR_LLM},
+	}
+	for _, tt := range tests {
+		if class := Classify(tt.err, tt.matching); class != tt.class {
+			t.Errorf("%v: %q, want %q", tt.err, class, tt.class)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package associations
+
+import (
+	"database/sql"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// an association loses half its weight when it isn't seen for this long
+	HALF_LIFE = 30 * 24 * time.Hour
+)
+
+// Graph counts which selected input words produced passages containing
+// which challenge words. Counts decay, so it follows the model and the
+// quotes currently played rather than everything ever played.
+type Graph struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Suggestion is a passage word and how strongly it led to a missing
+// challenge word before.
+type Suggestion struct {
+	Word   string  `json:"word"`
+	Target string  `json:"target"`
+	Score  float64 `json:"score"`
+}
+
+func New(db *sql.DB) *Graph {
+	return &Graph{db: db, now: time.Now}
+}
+
+func decay(weight float64, since time.Duration) float64 {
+	return weight * math.Pow(0.5, float64(since)/float64(HALF_LIFE))
+}
+
+// Record adds one attempt of a challenge: the words the player selected and
+// the entry generated from them.
+func (g *Graph) Record(challenge m.Challenge, entry m.Entry) error {
+	language := challenge.GetLanguage()
+	inputs := g.inputWords(challenge, entry.Input)
+	var targets []string
+	for _, match := range entry.Matches {
+		if word := challenge.Words[match.Target]; !slices.Contains(targets, word) {
+			targets = append(targets, word)
+		}
+	}
+	if len(inputs) == 0 || len(targets) == 0 {
+		return nil
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := g.now()
+	for _, input := range inputs {
+		for _, target := range targets {
+			var weight float64
+			var updated time.Time
+			err := tx.QueryRow(`SELECT weight, updated_at FROM word_associations
+				WHERE language = ? AND input = ? AND target = ?`, language, input, target).Scan(&weight, &updated)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			weight = decay(weight, now.Sub(updated)) + 1
+			_, err = tx.Exec(`INSERT INTO word_associations (language, input, target, weight, updated_at)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (language, input, target) DO UPDATE SET weight = excluded.weight, updated_at = excluded.updated_at`,
+				language, input, target, weight, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Rank orders the words of a passage by how often they led to the missing
+// challenge words before, best first.
+func (g *Graph) Rank(challenge m.Challenge, passage string, missing []string, limit int) ([]Suggestion, error) {
+	words := g.inputWords(challenge, passage)
+	if len(words) == 0 || len(missing) == 0 {
+		return nil, nil
+	}
+	query := `SELECT input, target, weight, updated_at FROM word_associations
+		WHERE language = ? AND input IN (` + placeholders(len(words)) + `) AND target IN (` + placeholders(len(missing)) + `)`
+	args := []any{challenge.GetLanguage()}
+	for _, word := range words {
+		args = append(args, word)
+	}
+	for _, word := range missing {
+		args = append(args, word)
+	}
+	rows, err := g.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// a word's score adds up over every missing target, the target shown is
+	// the one it leads to most
+	now := g.now()
+	byWord := make(map[string]*Suggestion)
+	best := make(map[string]float64)
+	for rows.Next() {
+		var input, target string
+		var weight float64
+		var updated time.Time
+		if err := rows.Scan(&input, &target, &weight, &updated); err != nil {
+			return nil, err
+		}
+		weight = decay(weight, now.Sub(updated))
+		suggestion, ok := byWord[input]
+		if !ok {
+			suggestion = &Suggestion{Word: input}
+			byWord[input] = suggestion
+		}
+		suggestion.Score += weight
+		if weight > best[input] || (weight == best[input] && target < suggestion.Target) {
+			best[input] = weight
+			suggestion.Target = target
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]Suggestion, 0, len(byWord))
+	for _, suggestion := range byWord {
+		suggestions = append(suggestions, *suggestion)
+	}
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Word, b.Word)
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// Strength sums how strongly any input led to each of targets before, a
+// target nothing ever led to is left out.
+func (g *Graph) Strength(language string, targets []string) (map[string]float64, error) {
+	strength := make(map[string]float64)
+	if len(targets) == 0 {
+		return strength, nil
+	}
+	args := []any{language}
+	for _, target := range targets {
+		args = append(args, target)
+	}
+	rows, err := g.db.Query(`SELECT target, weight, updated_at FROM word_associations
+		WHERE language = ? AND target IN (`+placeholders(len(targets))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	now := g.now()
+	for rows.Next() {
+		var target string
+		var weight float64
+		var updated time.Time
+		if err := rows.Scan(&target, &weight, &updated); err != nil {
+			return nil, err
+		}
+		strength[target] += decay(weight, now.Sub(updated))
+	}
+	return strength, rows.Err()
+}
+
+// inputWords are the distinct words of text worth associating, stopwords
+// lead everywhere and say nothing.
+func (g *Graph) inputWords(challenge m.Challenge, text string) []string {
+	set := stopwords.Get(challenge.GetLanguage())
+	var words []string
+	for _, word := range tokenizer.Words(tokenizer.Tokenize(text, challenge.TokenizerOptions())) {
+		if !set.Contains(word) && !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
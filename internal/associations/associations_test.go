@@ -0,0 +1,83 @@
+package associations
+
+import (
+	"testing"
+	"time"
+
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func testGraph(t *testing.T) *Graph {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	g := New(db)
+	return g
+}
+
+func TestRank(t *testing.T) {
+	g := testGraph(t)
+	challenge := m.Challenge{Words: []string{"beauty", "dreams", "future"}}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	record := func(input string, targets ...int) {
+		entry := m.Entry{Input: input}
+		for _, target := range targets {
+			entry.Matches = append(entry.Matches, m.WordMatch{Target: target})
+		}
+		if err := g.Record(challenge, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("sleep night", 1)
+	record("sleep the vision", 1, 2)
+	record("night", 1)
+	record("flowers", 0)
+
+	got, err := g.Rank(challenge, "A vision of night and sleep, then flowers.", []string{"dreams", "future"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Suggestion{
+		{Word: "sleep", Target: "dreams", Score: 3},
+		{Word: "night", Target: "dreams", Score: 2},
+		{Word: "vision", Target: "dreams", Score: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// one half-life later the old counts are worth half a fresh one
+	now = now.Add(HALF_LIFE)
+	record("flowers", 2)
+	got, err = g.Rank(challenge, "flowers and night", []string{"future", "dreams"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Word != "flowers" || got[0].Target != "future" || got[0].Score != 1 {
+		t.Errorf("got %v, want flowers leading to future", got)
+	}
+	strength, err := g.Strength("en", []string{"dreams", "future", "beauty", "night"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dreams had 5 counts and future 2, all a half-life old, plus a fresh one
+	wantStrength := map[string]float64{"dreams": 2.5, "future": 2, "beauty": 0.5}
+	for target, weight := range wantStrength {
+		if strength[target] != weight {
+			t.Errorf("%s: got %v, want %v", target, strength[target], weight)
+		}
+	}
+	if _, ok := strength["night"]; ok {
+		t.Error("nothing led to night")
+	}
+}
@@ -0,0 +1,79 @@
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/kirtansoni/words-weave/internal/share"
+)
+
+var (
+	// the size link previews expect
+	WIDTH  = 1200
+	HEIGHT = 630
+	MARGIN = 60
+
+	BACKGROUND = color.RGBA{0x12, 0x12, 0x13, 0xff}
+	FOREGROUND = color.RGBA{0xf8, 0xf8, 0xf8, 0xff}
+	MUTED      = color.RGBA{0x81, 0x83, 0x84, 0xff}
+	// same order as share.SQUARES, by words an attempt found
+	SQUARES = []color.RGBA{
+		{0x3a, 0x3a, 0x3c, 0xff},
+		{0xc9, 0xb4, 0x58, 0xff},
+		{0xe4, 0x88, 0x2b, 0xff},
+		{0x6a, 0xaa, 0x64, 0xff},
+	}
+	HINT = color.RGBA{0x5b, 0x8d, 0xef, 0xff}
+)
+
+// Render draws the share card of a summary as a PNG.
+func Render(w io.Writer, summary share.Summary) error {
+	img := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	draw.Draw(img, img.Bounds(), image.NewUniform(BACKGROUND), image.Point{}, draw.Src)
+
+	drawText(img, image.Pt(MARGIN, MARGIN), fmt.Sprintf("%s #%d", share.TITLE, summary.Day), 8, FOREGROUND)
+
+	// one row of squares per challenge, all rows share the square size of
+	// the longest one
+	columns := 1
+	for _, result := range summary.Challenges {
+		columns = max(columns, len(result.Unlocked)+result.Hints)
+	}
+	left := MARGIN + textWidth("0.", 5) + 40
+	size := min(56, (WIDTH-MARGIN-left)*6/(columns*7))
+	gap := max(2, size/6)
+	// labels shrink with the squares so rows don't run into each other
+	scale := min(5, max(2, size/GLYPH_HEIGHT))
+	y := 170
+	for _, result := range summary.Challenges {
+		label := fmt.Sprintf("%d.", result.Challenge+1)
+		drawText(img, image.Pt(MARGIN, y+(size-GLYPH_HEIGHT*scale)/2), label, scale, MUTED)
+		x := left
+		square := func(c color.Color) {
+			draw.Draw(img, image.Rect(x, y, x+size, y+size), image.NewUniform(c), image.Point{}, draw.Src)
+			x += size + gap
+		}
+		for _, n := range result.Unlocked {
+			square(SQUARES[min(n, len(SQUARES)-1)])
+		}
+		for range result.Hints {
+			square(HINT)
+		}
+		y += size + max(16, 2*gap)
+		if y+size > HEIGHT-MARGIN-GLYPH_HEIGHT*6-20 {
+			break
+		}
+	}
+
+	footer := HEIGHT - MARGIN - GLYPH_HEIGHT*6
+	drawText(img, image.Pt(MARGIN, footer), fmt.Sprintf("score %d", summary.Score), 6, FOREGROUND)
+	if summary.Streak > 0 {
+		streak := fmt.Sprintf("streak %d", summary.Streak)
+		drawText(img, image.Pt(WIDTH-MARGIN-textWidth(streak, 6), footer), streak, 6, FOREGROUND)
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,45 @@
+package card
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/share"
+)
+
+func TestFont(t *testing.T) {
+	for _, r := range "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789#.:-/()" {
+		if font[r] == (glyph{}) {
+			t.Errorf("no glyph for %q", r)
+		}
+	}
+	if got := font['T'][0]; got != 0b11111 {
+		t.Errorf("top row of T is %05b", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	summary := share.New("abc", 12, "en", []m.Result{
+		{Challenge: 0, Unlocked: []int{2, 0, 1, 5}, Solved: true, Score: 100},
+		{Challenge: 1, Unlocked: make([]int, 25), Hints: 2},
+	})
+	summary.Streak = 4
+	var b bytes.Buffer
+	if err := Render(&b, summary); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != WIDTH || size.Y != HEIGHT {
+		t.Errorf("card is %v", size)
+	}
+	// the first attempt found two words
+	left := MARGIN + textWidth("0.", 5) + 40
+	if r, g, bl, _ := img.At(left+1, 171).RGBA(); r>>8 != uint32(SQUARES[2].R) || g>>8 != uint32(SQUARES[2].G) || bl>>8 != uint32(SQUARES[2].B) {
+		t.Errorf("first square is %v", img.At(left+1, 171))
+	}
+}
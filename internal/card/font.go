@@ -0,0 +1,77 @@
+package card
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	GLYPH_WIDTH  = 5
+	GLYPH_HEIGHT = 7
+)
+
+var (
+	//go:embed font5x7.txt
+	fontFile string
+	font     = parseFont(fontFile)
+)
+
+// glyph rows from the top, bit 4 is the leftmost pixel
+type glyph [GLYPH_HEIGHT]uint8
+
+func parseFont(src string) map[rune]glyph {
+	glyphs := make(map[rune]glyph)
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		// "#" alone is the glyph for '#', longer lines are comments
+		if utf8.RuneCountInString(line) != 1 {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(line)
+		var g glyph
+		for row := 0; row < GLYPH_HEIGHT && i+1 < len(lines); row++ {
+			i++
+			for col, c := range strings.TrimRight(lines[i], "\r") {
+				if c == '#' && col < GLYPH_WIDTH {
+					g[row] |= 1 << (GLYPH_WIDTH - 1 - col)
+				}
+			}
+		}
+		glyphs[r] = g
+	}
+	return glyphs
+}
+
+// textWidth is the width of text drawn at scale, glyphs are one pixel apart.
+func textWidth(text string, scale int) int {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return 0
+	}
+	return (n*(GLYPH_WIDTH+1) - 1) * scale
+}
+
+// drawText draws text with its top left corner at p, each font pixel a
+// scale by scale square. Characters missing from the font are left blank.
+func drawText(img draw.Image, p image.Point, text string, scale int, c color.Color) {
+	src := image.NewUniform(c)
+	x := p.X
+	for _, r := range strings.ToUpper(text) {
+		g := font[r]
+		for row := range GLYPH_HEIGHT {
+			for col := range GLYPH_WIDTH {
+				if g[row]&(1<<(GLYPH_WIDTH-1-col)) == 0 {
+					continue
+				}
+				px := image.Rect(x+col*scale, p.Y+row*scale, x+(col+1)*scale, p.Y+(row+1)*scale)
+				draw.Draw(img, px, src, image.Point{}, draw.Src)
+			}
+		}
+		x += (GLYPH_WIDTH + 1) * scale
+	}
+}
@@ -0,0 +1,135 @@
+package dataio
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	s "github.com/kirtansoni/words-weave/internal/models"
+)
+
+var (
+	DAY_FORMAT   = "2006-01-02"
+	ErrSlotTaken = errors.New("slot already has a challenge")
+)
+
+// Today is the day whose challenges are played at t, days roll over at
+// midnight UTC.
+func Today(t time.Time) string {
+	return t.UTC().Format(DAY_FORMAT)
+}
+
+// ArchiveDay is a past day and how many challenges it had per language.
+type ArchiveDay struct {
+	Date      string         `json:"date"`
+	Languages map[string]int `json:"languages"`
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// ScheduleChallenge puts a challenge into the slot of its Date, Language
+// and Ordinal, returning its id. A taken slot is an ErrSlotTaken.
+func ScheduleChallenge(db *sql.DB, challenge s.Challenge) (int, error) {
+	return scheduleChallenge(db, challenge)
+}
+
+func scheduleChallenge(q queryer, challenge s.Challenge) (int, error) {
+	if _, err := time.Parse(DAY_FORMAT, challenge.Date); err != nil {
+		return 0, fmt.Errorf("challenge date %q: %w", challenge.Date, err)
+	}
+	language := challenge.GetLanguage()
+	var id int
+	err := q.QueryRow(`SELECT id FROM quotes WHERE play_date = ? AND language = ? AND ordinal = ?`,
+		challenge.Date, language, challenge.Ordinal).Scan(&id)
+	if err == nil {
+		return 0, fmt.Errorf("%w: %s %s #%d is quote %d", ErrSlotTaken, challenge.Date, language, challenge.Ordinal, id)
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+	res, err := q.Exec(`INSERT INTO quotes (quote, author, content, play_date, ordinal, language, words, matching, stopwords, tokenizer, difficulty)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		challenge.Quote, challenge.Author, challenge.Content, challenge.Date, challenge.Ordinal, language,
+		toJSON(challenge.Words), challenge.Matching, challenge.Stopwords, toJSON(challenge.Tokenizer), challenge.Difficulty)
+	if err != nil {
+		return 0, err
+	}
+	inserted, err := res.LastInsertId()
+	return int(inserted), err
+}
+
+// SaveChallenges schedules a day's challenges, numbered in order within
+// each language. Nothing is saved if any slot is taken.
+func SaveChallenges(db *sql.DB, date string, challenges []s.Challenge) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ordinals := make(map[string]int)
+	for _, challenge := range challenges {
+		challenge.Date = date
+		challenge.Ordinal = ordinals[challenge.GetLanguage()]
+		ordinals[challenge.GetLanguage()]++
+		if _, err := scheduleChallenge(tx, challenge); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetChallenges returns the challenges scheduled for date, by language and
+// ordinal.
+func GetChallenges(db *sql.DB, date string) ([]s.Challenge, error) {
+	rows, err := db.Query(`SELECT `+QUOTE_COLUMNS+`
+		FROM quotes WHERE play_date = ? AND status IN (?, ?) ORDER BY language, ordinal`, date, QUOTE_LIVE, QUOTE_RETIRED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var challenges []s.Challenge
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, q.Challenge)
+	}
+	return challenges, rows.Err()
+}
+
+// HasSchedule tells whether any challenge was ever scheduled.
+func HasSchedule(db *sql.DB) (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM quotes WHERE play_date IS NOT NULL`).Scan(&n)
+	return n > 0, err
+}
+
+// ArchiveDays lists the days before date that had challenges, newest
+// first.
+func ArchiveDays(db *sql.DB, before string, limit, offset int) ([]ArchiveDay, error) {
+	rows, err := db.Query(`SELECT play_date, language, COUNT(*) FROM quotes
+		WHERE play_date IN (SELECT DISTINCT play_date FROM quotes WHERE play_date < ? ORDER BY play_date DESC LIMIT ? OFFSET ?)
+		GROUP BY play_date, language ORDER BY play_date DESC, language`, before, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	days := []ArchiveDay{}
+	for rows.Next() {
+		var date, language string
+		var n int
+		if err := rows.Scan(&date, &language, &n); err != nil {
+			return nil, err
+		}
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, ArchiveDay{Date: date, Languages: make(map[string]int)})
+		}
+		days[len(days)-1].Languages[language] = n
+	}
+	return days, rows.Err()
+}
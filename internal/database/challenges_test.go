@@ -0,0 +1,63 @@
+package dataio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	s "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+func TestSchedule(t *testing.T) {
+	db, err := Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	day := []s.Challenge{
+		{Quote: "Practice makes perfect.", Author: "Proverb", Content: "Start here.", Words: []string{"practice", "makes", "perfect"}},
+		{Quote: "Hola mundo.", Content: "Empieza aquí.", Words: []string{"hola", "mundo"}, Language: "es"},
+		{Quote: "Less is more.", Content: "Begin.", Words: []string{"less", "is", "more"}, Stopwords: s.StopwordsExclude,
+			Tokenizer: tokenizer.Options{Hyphens: tokenizer.HyphenJoin}},
+	}
+	if err := SaveChallenges(db, "2026-03-01", day); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveChallenges(db, "2026-02-27", day[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetChallenges(db, "2026-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Language != "en" || got[1].Ordinal != 1 || got[2].Language != "es" {
+		t.Fatalf("got %+v", got)
+	}
+	if !reflect.DeepEqual(got[1].Words, day[2].Words) || got[1].Tokenizer != day[2].Tokenizer || got[1].Stopwords != s.StopwordsExclude {
+		t.Errorf("metadata lost: %+v", got[1])
+	}
+
+	// the English slot is taken, nothing of the day is saved
+	clash := []s.Challenge{{Quote: "a"}, {Quote: "b"}}
+	if err := SaveChallenges(db, "2026-02-27", clash); !errors.Is(err, ErrSlotTaken) {
+		t.Errorf("clash: %v", err)
+	}
+	if got, _ := GetChallenges(db, "2026-02-27"); len(got) != 1 {
+		t.Errorf("partial save: %+v", got)
+	}
+
+	days, err := ArchiveDays(db, "2026-03-02", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ArchiveDay{
+		{Date: "2026-03-01", Languages: map[string]int{"en": 2, "es": 1}},
+		{Date: "2026-02-27", Languages: map[string]int{"en": 1}},
+	}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("archive %+v", days)
+	}
+}
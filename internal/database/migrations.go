@@ -0,0 +1,164 @@
+package dataio
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// NNNN_name.sql, applied in order of NNNN. Never edit one that shipped,
+	// add a new one instead.
+	//go:embed migrations/*.sql
+	migrationFiles embed.FS
+)
+
+type Migration struct {
+	Version  int
+	Name     string
+	SQL      string
+	Checksum string
+}
+
+// MigrationStatus is a migration and when it was applied, zero if pending.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// loadMigrations reads the migrations of fsys, sorted by version.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".sql")
+		number, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with its version number", file)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(b)
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(b), Checksum: hex.EncodeToString(sum[:])})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	);`)
+	return err
+}
+
+// status pairs every known migration with its row in schema_migrations. It
+// fails when an applied migration was edited or is unknown to this build.
+func status(db *sql.DB, fsys fs.FS) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]MigrationStatus)
+	for rows.Next() {
+		var s MigrationStatus
+		if err := rows.Scan(&s.Version, &s.Name, &s.Checksum, &s.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[s.Version] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i].Migration = migration
+		a, ok := applied[migration.Version]
+		if !ok {
+			continue
+		}
+		if a.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %s was edited after it was applied", migration.Name)
+		}
+		statuses[i].AppliedAt = a.AppliedAt
+		delete(applied, migration.Version)
+	}
+	for _, a := range applied {
+		return nil, fmt.Errorf("database has migration %s which this build doesn't know, it is newer than the code", a.Name)
+	}
+	return statuses, nil
+}
+
+func migrate(db *sql.DB, fsys fs.FS) ([]Migration, error) {
+	statuses, err := status(db, fsys)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, s := range statuses {
+		if !s.AppliedAt.IsZero() {
+			continue
+		}
+		// a migration and its record go in together or not at all
+		tx, err := db.Begin()
+		if err != nil {
+			return done, err
+		}
+		if _, err := tx.Exec(s.SQL); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("migration %s: %w", s.Name, err)
+		}
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			s.Version, s.Name, s.Checksum, time.Now())
+		if err != nil {
+			tx.Rollback()
+			return done, err
+		}
+		if err := tx.Commit(); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// Migrate applies the pending migrations and returns them.
+func Migrate(db *sql.DB) ([]Migration, error) {
+	return migrate(db, migrationFiles)
+}
+
+// Status lists every migration, applied or not.
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	return status(db, migrationFiles)
+}
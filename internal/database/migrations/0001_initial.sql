@@ -0,0 +1,20 @@
+-- tables that predate migrations, IF NOT EXISTS adopts databases made by
+-- the old InitDB
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id TEXT NOT NULL,            -- Session ID (not unique, so multiple snapshots can exist)
+	snapshot_id TEXT PRIMARY KEY, -- Unique snapshot identifier
+	challenge INTEGER NOT NULL,
+	progress TEXT NOT NULL,      -- Store as JSON string
+	content TEXT NOT NULL,       -- Store as JSON string
+	attempts INTEGER NOT NULL,
+	last_accessed TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS quotes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	quote TEXT NOT NULL,
+	author TEXT NOT NULL,
+	content TEXT NOT NULL,
+	date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS word_associations (
+	language TEXT NOT NULL,
+	input TEXT NOT NULL,
+	target TEXT NOT NULL,
+	weight REAL NOT NULL,         -- decayed count as of updated_at
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (language, input, target)
+);
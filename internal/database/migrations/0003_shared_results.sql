@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS shared_results (
+	id TEXT PRIMARY KEY,
+	day INTEGER NOT NULL,
+	summary TEXT NOT NULL,       -- Store as JSON string
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
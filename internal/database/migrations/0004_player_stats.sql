@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS player_results (
+	player TEXT NOT NULL,
+	day TEXT NOT NULL,           -- YYYY-MM-DD in the player's timezone
+	language TEXT NOT NULL,
+	challenge INTEGER NOT NULL,
+	mode TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	solved BOOLEAN NOT NULL,
+	score INTEGER NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (player, day, language, challenge)
+);
+
+CREATE TABLE IF NOT EXISTS player_stats (
+	player TEXT PRIMARY KEY,
+	played INTEGER NOT NULL,
+	won INTEGER NOT NULL,
+	total_attempts INTEGER NOT NULL,
+	current_streak INTEGER NOT NULL,
+	max_streak INTEGER NOT NULL,
+	last_day TEXT NOT NULL,       -- last day with a win, '' before the first
+	histogram TEXT NOT NULL,      -- Store as JSON string
+	updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS leaderboard_players (
+	player TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	flagged BOOLEAN NOT NULL DEFAULT FALSE,
+	joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_entries (
+	board TEXT NOT NULL,
+	period TEXT NOT NULL,        -- day, ISO week or '' for all time
+	player TEXT NOT NULL,
+	score INTEGER NOT NULL,
+	solved INTEGER NOT NULL,
+	attempts INTEGER NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (board, period, player)
+);
+
+CREATE INDEX IF NOT EXISTS leaderboard_by_score ON leaderboard_entries (board, period, score DESC);
@@ -0,0 +1,13 @@
+-- quotes become scheduled challenges, rows without a play_date are not
+-- scheduled yet and past days stay as the archive
+
+ALTER TABLE quotes ADD COLUMN play_date TEXT;                 -- YYYY-MM-DD, UTC like the daily rollover
+ALTER TABLE quotes ADD COLUMN ordinal INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN language TEXT NOT NULL DEFAULT 'en';
+ALTER TABLE quotes ADD COLUMN words TEXT NOT NULL DEFAULT '[]';    -- target words as JSON
+ALTER TABLE quotes ADD COLUMN matching TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN stopwords TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN tokenizer TEXT NOT NULL DEFAULT '{}'; -- tokenizer options as JSON
+
+-- one challenge per slot of a day
+CREATE UNIQUE INDEX quotes_slot ON quotes (play_date, language, ordinal) WHERE play_date IS NOT NULL;
@@ -0,0 +1,22 @@
+-- practice replays of archived days count apart from the daily play of
+-- the same day, sqlite can't change a primary key in place
+
+CREATE TABLE player_results_new (
+	player TEXT NOT NULL,
+	day TEXT NOT NULL,           -- YYYY-MM-DD in the player's timezone, the archived day for practice
+	practice BOOLEAN NOT NULL DEFAULT FALSE,
+	language TEXT NOT NULL,
+	challenge INTEGER NOT NULL,
+	mode TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	solved BOOLEAN NOT NULL,
+	score INTEGER NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (player, day, practice, language, challenge)
+);
+
+INSERT INTO player_results_new (player, day, language, challenge, mode, attempts, solved, score, finished_at)
+	SELECT player, day, language, challenge, mode, attempts, solved, score, finished_at FROM player_results;
+
+DROP TABLE player_results;
+ALTER TABLE player_results_new RENAME TO player_results;
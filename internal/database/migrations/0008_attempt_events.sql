@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS attempt_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session TEXT NOT NULL,
+	day TEXT NOT NULL,
+	practice BOOLEAN NOT NULL,
+	language TEXT NOT NULL,
+	challenge INTEGER NOT NULL,
+	attempt INTEGER NOT NULL,        -- 1 for the first attempt of the challenge
+	parent INTEGER NOT NULL,         -- entry the input was picked from, -1 for the starting content
+	input_words TEXT NOT NULL,       -- JSON array
+	output TEXT NOT NULL,
+	matched TEXT NOT NULL,           -- JSON array of challenge word indexes found first by this attempt
+	latency_ms INTEGER NOT NULL,
+	model TEXT NOT NULL,
+	prompt_version TEXT NOT NULL,
+	cache_hit BOOLEAN NOT NULL,
+	error_class TEXT NOT NULL,       -- '' when the attempt went through
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attempt_events_by_challenge ON attempt_events (day, language, challenge);
+
+CREATE TRIGGER IF NOT EXISTS attempt_events_no_update BEFORE UPDATE ON attempt_events
+BEGIN
+	SELECT RAISE(ABORT, 'attempt_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS attempt_events_no_delete BEFORE DELETE ON attempt_events
+BEGIN
+	SELECT RAISE(ABORT, 'attempt_events is append-only');
+END;
@@ -0,0 +1,2 @@
+-- difficulty label a challenge pack gave the quote, '' when unlabelled
+ALTER TABLE quotes ADD COLUMN difficulty TEXT NOT NULL DEFAULT '';
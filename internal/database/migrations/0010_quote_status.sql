@@ -0,0 +1,7 @@
+-- where a quote is in its life: 'new' ones wait for starting content,
+-- 'draft' ones for an admin to approve them and 'live' ones are played
+ALTER TABLE quotes ADD COLUMN status TEXT NOT NULL DEFAULT 'live';
+-- what the starting content is about, so days don't repeat topics
+ALTER TABLE quotes ADD COLUMN topic TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX quotes_status ON quotes (status);
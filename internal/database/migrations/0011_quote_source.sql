@@ -0,0 +1,3 @@
+-- where an imported quote comes from and under which terms it may be used
+ALTER TABLE quotes ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN license TEXT NOT NULL DEFAULT '';
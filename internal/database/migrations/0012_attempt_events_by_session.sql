@@ -0,0 +1,2 @@
+-- the attempt history of a session, for the admin API
+CREATE INDEX IF NOT EXISTS attempt_events_by_session ON attempt_events (session);
@@ -0,0 +1,4 @@
+-- scripted looking results are dropped instead of flagging the player for
+-- good, rows made only to flag players who never opted in go too
+DELETE FROM leaderboard_players WHERE flagged AND name = 'flagged-' || player;
+ALTER TABLE leaderboard_players DROP COLUMN flagged;
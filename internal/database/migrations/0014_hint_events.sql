@@ -0,0 +1,2 @@
+-- hints are logged along with attempts, a revealed word is found by its hint
+ALTER TABLE attempt_events ADD COLUMN hint TEXT NOT NULL DEFAULT ''; -- tier of the hint taken, '' for an attempt
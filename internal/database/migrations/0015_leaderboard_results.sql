@@ -0,0 +1,8 @@
+-- challenges already on the boards, a result recorded again isn't added twice
+CREATE TABLE IF NOT EXISTS leaderboard_results (
+	player TEXT NOT NULL,
+	day TEXT NOT NULL,           -- day of the challenge set
+	language TEXT NOT NULL,
+	challenge INTEGER NOT NULL,
+	PRIMARY KEY (player, day, language, challenge)
+);
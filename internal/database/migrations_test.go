@@ -0,0 +1,65 @@
+package dataio
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := Open(":memory:", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fsys := fstest.MapFS{
+		"migrations/0001_notes.sql":  {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT);")},
+		"migrations/0002_author.sql": {Data: []byte("ALTER TABLE notes ADD COLUMN author TEXT;")},
+	}
+
+	done, err := migrate(db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 || done[0].Name != "0001_notes" {
+		t.Fatalf("applied %+v", done)
+	}
+	if done, err := migrate(db, fsys); err != nil || len(done) != 0 {
+		t.Fatalf("second run applied %v, %v", done, err)
+	}
+	if _, err := db.Exec("INSERT INTO notes (body, author) VALUES ('hi', 'me')"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failing migration leaves no trace
+	fsys["migrations/0003_broken.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tags (id INTEGER); CREATE TABLE oops (")}
+	if _, err := migrate(db, fsys); err == nil {
+		t.Fatal("broken migration applied")
+	}
+	if _, err := db.Exec("SELECT * FROM tags"); err == nil {
+		t.Error("broken migration was partly applied")
+	}
+	delete(fsys, "migrations/0003_broken.sql")
+
+	fsys["migrations/0001_notes.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY);")}
+	if _, err := migrate(db, fsys); err == nil || !strings.Contains(err.Error(), "edited") {
+		t.Errorf("edited migration: %v", err)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	db, err := Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	statuses, err := Status(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range statuses {
+		if s.Version != i+1 || s.AppliedAt.IsZero() {
+			t.Errorf("migration %s: version %d, applied %v", s.Name, s.Version, s.AppliedAt)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package dataio
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	s "github.com/kirtansoni/words-weave/internal/models"
+)
+
+const (
+	QUOTE_NEW   = "new"
+	QUOTE_DRAFT = "draft"
+	QUOTE_LIVE  = "live"
+	// never scheduled again, days it was played keep it
+	QUOTE_RETIRED = "retired"
+)
+
+var (
+	ErrNotDraft    = errors.New("quote is not a draft")
+	ErrNoQuote     = errors.New("no such quote")
+	ErrQuotePlayed = errors.New("quote was played already")
+	ErrRetired     = errors.New("quote is retired")
+	ErrNoContent   = errors.New("quote has no starting content")
+	ErrPastDay     = errors.New("day is over")
+	ErrDayFull     = errors.New("day has no free slot")
+	// columns scanQuote reads, in order
+	QUOTE_COLUMNS = `id, quote, author, content, COALESCE(play_date, ''), ordinal, language, words,
+		matching, stopwords, tokenizer, difficulty, status, topic, source, license`
+)
+
+// Quote is a row of the quotes table, scheduled or not.
+type Quote struct {
+	s.Challenge
+	Status string `json:"status"`
+	Topic  string `json:"topic"`
+	// where an imported quote comes from and its licensing terms
+	Source  string `json:"source"`
+	License string `json:"license"`
+}
+
+func scanQuote(rows *sql.Rows) (Quote, error) {
+	var q Quote
+	var words, tokenizer string
+	err := rows.Scan(&q.ID, &q.Quote, &q.Author, &q.Content, &q.Date, &q.Ordinal, &q.Language, &words,
+		&q.Matching, &q.Stopwords, &tokenizer, &q.Difficulty, &q.Status, &q.Topic, &q.Source, &q.License)
+	if err != nil {
+		return q, err
+	}
+	if err := json.Unmarshal([]byte(words), &q.Words); err != nil {
+		return q, fmt.Errorf("quote %d words: %w", q.ID, err)
+	}
+	if err := json.Unmarshal([]byte(tokenizer), &q.Tokenizer); err != nil {
+		return q, fmt.Errorf("quote %d tokenizer: %w", q.ID, err)
+	}
+	return q, nil
+}
+
+// AddQuote stores a quote that isn't scheduled yet and returns its id.
+func AddQuote(db *sql.DB, q Quote) (int, error) {
+	return addQuote(db, q)
+}
+
+func addQuote(e queryer, q Quote) (int, error) {
+	if q.Status == "" {
+		q.Status = QUOTE_NEW
+	}
+	res, err := e.Exec(`INSERT INTO quotes (quote, author, content, language, words, matching, stopwords, tokenizer, difficulty, status, topic, source, license)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		q.Quote, q.Author, q.Content, q.GetLanguage(), toJSON(q.Words), q.Matching, q.Stopwords, toJSON(q.Tokenizer),
+		q.Difficulty, q.Status, q.Topic, q.Source, q.License)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// AddQuotes stores quotes that aren't scheduled yet, all of them or none.
+func AddQuotes(db *sql.DB, quotes []Quote) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, q := range quotes {
+		if _, err := addQuote(tx, q); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// QuoteTexts lists the text of every quote by language, whatever its
+// status.
+func QuoteTexts(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`SELECT language, quote FROM quotes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	texts := make(map[string][]string)
+	for rows.Next() {
+		var language, text string
+		if err := rows.Scan(&language, &text); err != nil {
+			return nil, err
+		}
+		texts[language] = append(texts[language], text)
+	}
+	return texts, rows.Err()
+}
+
+// GetQuotes lists the quotes with status, oldest first. No limit when
+// limit is 0.
+func GetQuotes(db *sql.DB, status string, limit int) ([]Quote, error) {
+	return ListQuotes(db, QuoteFilter{Status: status, Limit: limit})
+}
+
+// QuoteFilter narrows ListQuotes, empty fields match every quote.
+type QuoteFilter struct {
+	Status string
+	// YYYY-MM-DD the quote is played on
+	Date   string
+	Limit  int
+	Offset int
+}
+
+func ListQuotes(db *sql.DB, filter QuoteFilter) ([]Quote, error) {
+	query := `SELECT ` + QUOTE_COLUMNS + ` FROM quotes WHERE 1 = 1`
+	var args []any
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.Date != "" {
+		query += ` AND play_date = ?`
+		args = append(args, filter.Date)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = -1
+	}
+	query += ` ORDER BY id LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	quotes := []Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+// SaveDraft gives a new quote its starting content, it then waits for an
+// admin to approve it.
+func SaveDraft(db *sql.DB, id int, content, topic string) error {
+	res, err := db.Exec(`UPDATE quotes SET content = ?, topic = ?, status = ? WHERE id = ? AND status = ?`,
+		content, topic, QUOTE_DRAFT, id, QUOTE_NEW)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("quote %d is not new", id)
+	}
+	return nil
+}
+
+// RecentTopics are the topics of drafts and of quotes played on or after
+// since.
+func RecentTopics(db *sql.DB, since string) ([]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT topic FROM quotes
+		WHERE topic != '' AND (status = ? OR play_date >= ?)`, QUOTE_DRAFT, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var topics []string
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	return topics, rows.Err()
+}
+
+// GetQuote is the quote with id, ErrNoQuote if there is none.
+func GetQuote(db *sql.DB, id int) (Quote, error) {
+	rows, err := db.Query(`SELECT `+QUOTE_COLUMNS+` FROM quotes WHERE id = ?`, id)
+	if err != nil {
+		return Quote{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Quote{}, err
+		}
+		return Quote{}, fmt.Errorf("%w: %d", ErrNoQuote, id)
+	}
+	return scanQuote(rows)
+}
+
+// UpdateQuote replaces the text, content and metadata of a quote. The
+// status and slot stay, a quote played before today can't change anymore.
+func UpdateQuote(db *sql.DB, q Quote, today string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var played string
+	err = tx.QueryRow(`SELECT COALESCE(play_date, '') FROM quotes WHERE id = ?`, q.ID).Scan(&played)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %d", ErrNoQuote, q.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if played != "" && played <= today {
+		return fmt.Errorf("%w: %d on %s", ErrQuotePlayed, q.ID, played)
+	}
+	_, err = tx.Exec(`UPDATE quotes SET quote = ?, author = ?, content = ?, language = ?, words = ?, matching = ?,
+		stopwords = ?, tokenizer = ?, difficulty = ?, topic = ?, source = ?, license = ? WHERE id = ?`,
+		q.Quote, q.Author, q.Content, q.GetLanguage(), toJSON(q.Words), q.Matching,
+		q.Stopwords, toJSON(q.Tokenizer), q.Difficulty, q.Topic, q.Source, q.License, q.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ScheduleQuote makes a quote live in the next free slot of its language
+// on date, which can't be over yet. A day has up to slots challenges per
+// language. A quote already played stays where it is.
+func ScheduleQuote(db *sql.DB, id int, date, today string, slots int) (int, error) {
+	if _, err := time.Parse(DAY_FORMAT, date); err != nil {
+		return 0, fmt.Errorf("date %q: %w", date, err)
+	}
+	if date < today {
+		return 0, fmt.Errorf("%w: %s", ErrPastDay, date)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var status, language, content, played string
+	err = tx.QueryRow(`SELECT status, language, content, COALESCE(play_date, '') FROM quotes WHERE id = ?`, id).
+		Scan(&status, &language, &content, &played)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, fmt.Errorf("%w: %d", ErrNoQuote, id)
+	case err != nil:
+		return 0, err
+	case status == QUOTE_RETIRED:
+		return 0, fmt.Errorf("%w: %d", ErrRetired, id)
+	case content == "":
+		return 0, fmt.Errorf("%w: %d", ErrNoContent, id)
+	case played != "" && played <= today:
+		return 0, fmt.Errorf("%w: %d on %s", ErrQuotePlayed, id, played)
+	}
+	ordinal, err := takeSlot(tx, id, language, date, slots)
+	if err != nil {
+		return 0, err
+	}
+	return ordinal, tx.Commit()
+}
+
+// RetireQuote keeps a quote from being scheduled again and takes it off
+// the days that haven't started.
+func RetireQuote(db *sql.DB, id int, today string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var played string
+	err = tx.QueryRow(`SELECT COALESCE(play_date, '') FROM quotes WHERE id = ?`, id).Scan(&played)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %d", ErrNoQuote, id)
+	}
+	if err != nil {
+		return err
+	}
+	if played > today {
+		if err := freeSlot(tx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE quotes SET status = ? WHERE id = ?`, QUOTE_RETIRED, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// freeSlot takes a quote off its day. The challenges after it move up, the
+// game plays a day's challenges by position and ordinals have to stay it.
+func freeSlot(tx *sql.Tx, id int) error {
+	var date sql.NullString
+	var language string
+	var ordinal int
+	err := tx.QueryRow(`SELECT play_date, language, ordinal FROM quotes WHERE id = ?`, id).Scan(&date, &language, &ordinal)
+	if err != nil || !date.Valid {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE quotes SET play_date = NULL WHERE id = ?`, id); err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT id FROM quotes WHERE play_date = ? AND language = ? AND ordinal > ? ORDER BY ordinal`,
+		date.String, language, ordinal)
+	if err != nil {
+		return err
+	}
+	var after []int
+	for rows.Next() {
+		var other int
+		if err := rows.Scan(&other); err != nil {
+			rows.Close()
+			return err
+		}
+		after = append(after, other)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// one at a time in order, the slots are unique
+	for _, other := range after {
+		if _, err := tx.Exec(`UPDATE quotes SET ordinal = ordinal - 1 WHERE id = ?`, other); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takeSlot moves a quote after the challenges of its language on date, the
+// day it leaves closes up behind it.
+func takeSlot(tx *sql.Tx, id int, language, date string, slots int) (int, error) {
+	if err := freeSlot(tx, id); err != nil {
+		return 0, err
+	}
+	var ordinal int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(ordinal) + 1, 0) FROM quotes WHERE play_date = ? AND language = ?`,
+		date, language).Scan(&ordinal); err != nil {
+		return 0, err
+	}
+	if ordinal >= slots {
+		return 0, fmt.Errorf("%w: %s %s", ErrDayFull, date, language)
+	}
+	_, err := tx.Exec(`UPDATE quotes SET play_date = ?, ordinal = ?, status = ? WHERE id = ?`, date, ordinal, QUOTE_LIVE, id)
+	return ordinal, err
+}
+
+// ApproveDraft makes a draft live in the next free slot of its language on
+// date, of up to slots, and returns its ordinal. Days before today are over.
+func ApproveDraft(db *sql.DB, id int, date, today string, slots int) (int, error) {
+	if _, err := time.Parse(DAY_FORMAT, date); err != nil {
+		return 0, fmt.Errorf("date %q: %w", date, err)
+	}
+	if date < today {
+		return 0, fmt.Errorf("%w: %s", ErrPastDay, date)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var status, language string
+	err = tx.QueryRow(`SELECT status, language FROM quotes WHERE id = ?`, id).Scan(&status, &language)
+	if err == sql.ErrNoRows || (err == nil && status != QUOTE_DRAFT) {
+		return 0, fmt.Errorf("%w: %d", ErrNotDraft, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	ordinal, err := takeSlot(tx, id, language, date, slots)
+	if err != nil {
+		return 0, err
+	}
+	return ordinal, tx.Commit()
+}
@@ -0,0 +1,137 @@
+package dataio
+
+import (
+	"errors"
+	"testing"
+
+	s "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestQuoteLifecycle(t *testing.T) {
+	db, err := Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	today := "2026-06-10"
+
+	played, err := ScheduleChallenge(db, s.Challenge{Quote: "Old.", Content: "x", Date: "2026-06-09"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare, err := AddQuote(db, Quote{Challenge: s.Challenge{Quote: "No content yet."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := AddQuote(db, Quote{Challenge: s.Challenge{Quote: "Drafted.", Content: "Some words."}, Status: QUOTE_DRAFT})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ScheduleQuote(db, bare, "2026-06-11", today, 3); !errors.Is(err, ErrNoContent) {
+		t.Errorf("scheduled without content: %v", err)
+	}
+	if _, err := ScheduleQuote(db, draft, "2026-06-01", today, 3); !errors.Is(err, ErrPastDay) {
+		t.Errorf("scheduled in the past: %v", err)
+	}
+	if _, err := ScheduleQuote(db, played, "2026-06-11", today, 3); !errors.Is(err, ErrQuotePlayed) {
+		t.Errorf("moved a played quote: %v", err)
+	}
+	if ordinal, err := ScheduleQuote(db, draft, "2026-06-11", today, 3); err != nil || ordinal != 0 {
+		t.Fatalf("schedule: %d %v", ordinal, err)
+	}
+
+	q, err := GetQuote(db, draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Status != QUOTE_LIVE || q.Date != "2026-06-11" {
+		t.Errorf("got %+v", q)
+	}
+	q.Author = "Someone"
+	if err := UpdateQuote(db, q, today); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := GetQuote(db, played)
+	if err := UpdateQuote(db, old, today); !errors.Is(err, ErrQuotePlayed) {
+		t.Errorf("edited a played quote: %v", err)
+	}
+
+	// retiring takes it off the days to come, played days keep theirs
+	for _, id := range []int{draft, played} {
+		if err := RetireQuote(db, id, today); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := GetChallenges(db, "2026-06-11"); len(got) != 0 {
+		t.Errorf("retired quote still scheduled: %+v", got)
+	}
+	if got, _ := GetChallenges(db, "2026-06-09"); len(got) != 1 {
+		t.Errorf("played day lost its quote: %+v", got)
+	}
+	if _, err := ScheduleQuote(db, draft, "2026-06-12", today, 3); !errors.Is(err, ErrRetired) {
+		t.Errorf("scheduled a retired quote: %v", err)
+	}
+	if _, err := GetQuote(db, 99); !errors.Is(err, ErrNoQuote) {
+		t.Errorf("missing quote: %v", err)
+	}
+	if retired, _ := ListQuotes(db, QuoteFilter{Status: QUOTE_RETIRED}); len(retired) != 2 || retired[1].Author != "Someone" {
+		t.Errorf("retired %+v", retired)
+	}
+}
+
+func TestSlotsCloseUp(t *testing.T) {
+	db, err := Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	today := "2026-06-10"
+	var ids []int
+	for _, text := range []string{"One.", "Two.", "Three.", "Four."} {
+		id, err := AddQuote(db, Quote{Challenge: s.Challenge{Quote: text, Content: "x"}, Status: QUOTE_DRAFT})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	for i, id := range ids[:3] {
+		if ordinal, err := ScheduleQuote(db, id, "2026-06-11", today, 3); err != nil || ordinal != i {
+			t.Fatalf("schedule %d: %d %v", id, ordinal, err)
+		}
+	}
+	if _, err := ScheduleQuote(db, ids[3], "2026-06-11", today, 3); !errors.Is(err, ErrDayFull) {
+		t.Errorf("scheduled past the last slot: %v", err)
+	}
+
+	quotes := func(date string) []string {
+		challenges, err := GetChallenges(db, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i, c := range challenges {
+			if c.Ordinal != i {
+				t.Errorf("%s: %q is #%d at position %d", date, c.Quote, c.Ordinal, i)
+			}
+			got = append(got, c.Quote)
+		}
+		return got
+	}
+	// retiring and moving leave no gaps behind
+	if err := RetireQuote(db, ids[0], today); err != nil {
+		t.Fatal(err)
+	}
+	if ordinal, err := ScheduleQuote(db, ids[1], "2026-06-12", today, 3); err != nil || ordinal != 0 {
+		t.Fatalf("move: %d %v", ordinal, err)
+	}
+	if ordinal, err := ScheduleQuote(db, ids[3], "2026-06-11", today, 3); err != nil || ordinal != 1 {
+		t.Fatalf("schedule after the gaps closed: %d %v", ordinal, err)
+	}
+	if got := quotes("2026-06-11"); len(got) != 2 || got[0] != "Three." || got[1] != "Four." {
+		t.Errorf("2026-06-11: %v", got)
+	}
+	if got := quotes("2026-06-12"); len(got) != 1 || got[0] != "Two." {
+		t.Errorf("2026-06-12: %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package frontend
 
 import (
+	"bytes"
 	"embed"
 	"io/fs"
 	"net/http"
@@ -34,3 +35,13 @@ func (a *ReactSPA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	fs := http.FileServer(http.FS(dist))
 	fs.ServeHTTP(w, r)
 }
+
+// Index is the SPA's index.html with head added at the end of its <head>,
+// for pages that need their own meta tags.
+func (a *ReactSPA) Index(head string) ([]byte, error) {
+	index, err := a.embeddings.ReadFile(a.buildDir + "/index.html")
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Replace(index, []byte("</head>"), []byte(head+"</head>"), 1), nil
+}
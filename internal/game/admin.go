@@ -0,0 +1,286 @@
+package game
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/packs"
+	"github.com/kirtansoni/words-weave/internal/password"
+	"github.com/kirtansoni/words-weave/internal/quality"
+	"github.com/kirtansoni/words-weave/internal/reports"
+)
+
+var (
+	REPORT_DAYS = 30
+	// failed admin logins an address gets before it has to wait, the wait
+	// doubles with every further failure up to ADMIN_MAX_BACKOFF
+	ADMIN_FAILURES    = 5
+	ADMIN_BACKOFF     = time.Second
+	ADMIN_MAX_BACKOFF = 15 * time.Minute
+	// password checks running at once, each takes a lot of CPU on purpose
+	ADMIN_CHECKS = 2
+)
+
+// adminGuard slows down guessing of admin credentials. The zero value is
+// ready to use.
+type adminGuard struct {
+	sync.Mutex
+	failures map[string]*adminFailure
+	checking atomic.Int32
+}
+
+type adminFailure struct {
+	count int
+	last  time.Time
+	until time.Time
+}
+
+// wait is how long the address has to wait before trying again, 0 when it
+// can try now.
+func (ag *adminGuard) wait(addr string, now time.Time) time.Duration {
+	ag.Lock()
+	defer ag.Unlock()
+	if f, ok := ag.failures[addr]; ok && now.Before(f.until) {
+		return f.until.Sub(now)
+	}
+	return 0
+}
+
+func (ag *adminGuard) fail(addr string, now time.Time) {
+	ag.Lock()
+	defer ag.Unlock()
+	if ag.failures == nil {
+		ag.failures = make(map[string]*adminFailure)
+	}
+	// addresses that stopped failing a while ago are forgotten
+	for other, f := range ag.failures {
+		if now.Sub(f.last) > ADMIN_MAX_BACKOFF && !now.Before(f.until) {
+			delete(ag.failures, other)
+		}
+	}
+	f, ok := ag.failures[addr]
+	if !ok {
+		f = &adminFailure{}
+		ag.failures[addr] = f
+	}
+	f.count++
+	f.last = now
+	if over := f.count - ADMIN_FAILURES; over >= 0 {
+		backoff := ADMIN_MAX_BACKOFF
+		if over < 30 {
+			backoff = min(ADMIN_BACKOFF<<over, ADMIN_MAX_BACKOFF)
+		}
+		f.until = now.Add(backoff)
+	}
+}
+
+func (ag *adminGuard) succeed(addr string) {
+	ag.Lock()
+	defer ag.Unlock()
+	delete(ag.failures, addr)
+}
+
+// check runs the password check unless too many already are.
+func (ag *adminGuard) check(hash, pass string) (ok, busy bool) {
+	defer ag.checking.Add(-1)
+	if ag.checking.Add(1) > int32(ADMIN_CHECKS) {
+		return false, true
+	}
+	return password.Check(hash, pass), false
+}
+
+func remoteAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func tooMany(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds()+0.999)))
+	http.Error(w, "Too many Requests", http.StatusTooManyRequests)
+}
+
+// Admin guards a handler of the /admin API. Callers send either
+// "Authorization: Bearer <AdminToken>" or basic auth as AdminUser. Without
+// either configured the admin API doesn't exist. Addresses that keep
+// failing have to wait before their next try.
+func (g *Game) Admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		basic := g.AdminUser != "" && g.AdminPassword != ""
+		if g.AdminToken == "" && !basic {
+			http.NotFound(w, r)
+			return
+		}
+		addr, now := remoteAddr(r), time.Now()
+		if wait := g.adminGuard.wait(addr, now); wait > 0 {
+			tooMany(w, wait)
+			return
+		}
+		tried := false
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && g.AdminToken != "" {
+			tried = true
+			if subtle.ConstantTimeCompare([]byte(token), []byte(g.AdminToken)) == 1 {
+				g.adminGuard.succeed(addr)
+				next(w, r)
+				return
+			}
+		}
+		if user, pass, ok := r.BasicAuth(); ok && basic {
+			tried = true
+			// the password is checked whatever the user, so timing doesn't tell users apart
+			userOK := subtle.ConstantTimeCompare([]byte(user), []byte(g.AdminUser)) == 1
+			passOK, busy := g.adminGuard.check(g.AdminPassword, pass)
+			if busy {
+				tooMany(w, time.Second)
+				return
+			}
+			if passOK && userOK {
+				g.adminGuard.succeed(addr)
+				next(w, r)
+				return
+			}
+		}
+		if tried {
+			g.adminGuard.fail(addr, now)
+		}
+		if g.AdminToken != "" {
+			w.Header().Add("WWW-Authenticate", `Bearer realm="admin"`)
+		}
+		if basic {
+			w.Header().Add("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+}
+
+// GET /admin/reports/difficulty?from=YYYY-MM-DD&to=YYYY-MM-DD&format=csv
+// The last REPORT_DAYS days by default.
+func (g *Game) Getdifficultyreport(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Reports Not Available", http.StatusNotFound)
+		return
+	}
+	queryParams := r.URL.Query()
+	now := time.Now()
+	from, to := queryParams.Get("from"), queryParams.Get("to")
+	if to == "" {
+		to = db.Today(now)
+	}
+	if from == "" {
+		from = db.Today(now.AddDate(0, 0, -REPORT_DAYS))
+	}
+	for _, day := range []string{from, to} {
+		if _, err := time.Parse(db.DAY_FORMAT, day); err != nil {
+			http.Error(w, "Days are YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := reports.Difficulty(g.DB, from, to)
+	if err != nil {
+		log.Printf("Difficulty report failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if queryParams.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="difficulty-`+from+`-`+to+`.csv"`)
+		if err := reports.WriteCSV(w, res); err != nil {
+			log.Printf("Writing difficulty report failed: %v", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// POST /admin/packs/check
+// Checks the starting content of a challenge pack before it is scheduled.
+func (g *Game) Postpackcheck(w http.ResponseWriter, r *http.Request) {
+	pack, err := packs.Parse(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid pack: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	reports, ok, err := pack.Check(quality.Validator{Stemmer: g.Stemmer, Graph: g.Associations})
+	if err != nil {
+		log.Printf("Checking pack failed: %v", err)
+		http.Error(w, "Stemmer unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		OK      bool             `json:"ok"`
+		Reports []quality.Report `json:"reports"`
+	}{ok, reports})
+}
+
+// GET /admin/drafts
+// Generated challenges waiting for approval.
+func (g *Game) Getdrafts(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Drafts Not Available", http.StatusNotFound)
+		return
+	}
+	drafts, err := db.GetQuotes(g.DB, db.QUOTE_DRAFT, 0)
+	if err != nil {
+		log.Printf("Listing drafts failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drafts)
+}
+
+// POST /admin/drafts/{id}/approve {"date": "YYYY-MM-DD"}
+// Schedules a draft after the challenges the day already has.
+func (g *Game) Postapprovedraft(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Drafts Not Available", http.StatusNotFound)
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid draft", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Date string `json:"date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse(db.DAY_FORMAT, req.Date); err != nil {
+		http.Error(w, "Days are YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	// the game plays challenges up to MAXCHALLENGES
+	ordinal, err := db.ApproveDraft(g.DB, id, req.Date, db.Today(time.Now()), MAXCHALLENGES+1)
+	if errors.Is(err, db.ErrNotDraft) {
+		http.Error(w, "No such draft", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, db.ErrDayFull) || errors.Is(err, db.ErrPastDay) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Approving draft %d failed: %v", id, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"id": id, "date": req.Date, "ordinal": ordinal})
+}
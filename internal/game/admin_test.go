@@ -0,0 +1,154 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/password"
+)
+
+func TestAdminAuth(t *testing.T) {
+	g := GetGame()
+	ok := g.Admin(func(w http.ResponseWriter, r *http.Request) {})
+	call := func(setup func(r *http.Request)) int {
+		r := httptest.NewRequest("GET", "/admin/live", nil)
+		setup(r)
+		w := httptest.NewRecorder()
+		ok(w, r)
+		return w.Code
+	}
+	none := func(r *http.Request) {}
+	if code := call(none); code != http.StatusNotFound {
+		t.Errorf("unconfigured: %d", code)
+	}
+
+	password.ITERATIONS = 1000
+	hash, err := password.Hash("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.AdminToken, g.AdminUser, g.AdminPassword = "tok", "ops", hash
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+		want  int
+	}{
+		{"nothing", none, http.StatusUnauthorized},
+		{"token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer tok") }, http.StatusOK},
+		{"wrong token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"basic", func(r *http.Request) { r.SetBasicAuth("ops", "s3cret") }, http.StatusOK},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("ops", "s3cret!") }, http.StatusUnauthorized},
+		{"wrong user", func(r *http.Request) { r.SetBasicAuth("root", "s3cret") }, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		if code := call(test.setup); code != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, code, test.want)
+		}
+	}
+	// guessing from one address makes it wait, others can still log in
+	wrong := func(r *http.Request) { r.SetBasicAuth("ops", "guess") }
+	guesses := 0
+	for call(wrong) == http.StatusUnauthorized && guesses < ADMIN_FAILURES {
+		guesses++
+	}
+	if guesses == ADMIN_FAILURES {
+		t.Errorf("%d failures in a row were never slowed down", guesses)
+	}
+	right := func(r *http.Request) { r.SetBasicAuth("ops", "s3cret") }
+	if code := call(right); code != http.StatusTooManyRequests {
+		t.Errorf("after %d failures: %d", ADMIN_FAILURES, code)
+	}
+	if code := call(func(r *http.Request) { right(r); r.RemoteAddr = "198.51.100.7:4000" }); code != http.StatusOK {
+		t.Errorf("other address: %d", code)
+	}
+}
+
+func TestAdminSessionsAndChallenges(t *testing.T) {
+	database, err := db.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	g := GetGame()
+	g.SetDB(database)
+	g.Init(context.Background())
+
+	do := func(handler http.HandlerFunc, method, url, body string, path map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		for key, value := range path {
+			r.SetPathValue(key, value)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	// a player starts playing
+	w := do(g.Getgamestate, "GET", "/game", "", nil)
+	sessionID := w.Result().Cookies()[0].Value
+	state, _ := g.SessionManager.GetState(sessionID)
+	state.LastAccessed = time.Now()
+
+	var live struct {
+		Day    string `json:"day"`
+		Active int    `json:"activePlayers"`
+	}
+	json.Unmarshal(do(g.Getlive, "GET", "/admin/live", "", nil).Body.Bytes(), &live)
+	if live.Active != 1 || live.Day != db.Today(time.Now()) {
+		t.Errorf("live %+v", live)
+	}
+	var session struct {
+		State   m.State `json:"state"`
+		History []any   `json:"history"`
+	}
+	w = do(g.Getsession, "GET", "/admin/sessions/"+sessionID, "", map[string]string{"key": sessionID})
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.State.ID != sessionID || session.History == nil {
+		t.Errorf("session %s", w.Body.String())
+	}
+	if w := do(g.Deletesession, "DELETE", "/admin/sessions/"+sessionID, "", map[string]string{"key": sessionID}); w.Code != http.StatusNoContent {
+		t.Errorf("reset: %d", w.Code)
+	}
+	if _, exists := g.SessionManager.GetState(sessionID); exists {
+		t.Error("session survived the reset")
+	}
+
+	// a new challenge scheduled for tomorrow is played after a forced rollover
+	w = do(g.Postquote, "POST", "/admin/challenges", `{"quote": "Well begun is half done.", "author": "Aristotle",
+		"content": "Ships leave the harbour at dawn."}`, nil)
+	var created struct {
+		Quote db.Quote `json:"quote"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.Quote.Status != db.QUOTE_DRAFT || len(created.Quote.Words) != 5 {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	id := map[string]string{"id": strconv.Itoa(created.Quote.ID)}
+	tomorrow := db.Today(time.Now().Add(24 * time.Hour))
+	if w := do(g.Postschedulequote, "POST", "/", `{"date": "`+tomorrow+`"}`, id); w.Code != http.StatusOK {
+		t.Fatalf("schedule: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(g.Patchquote, "PATCH", "/", `{"author": "Plato"}`, id); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Plato") {
+		t.Errorf("edit: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(g.Postrollover, "POST", "/admin/rollover", `{"date": "`+tomorrow+`"}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("rollover: %d %s", w.Code, w.Body.String())
+	}
+	if g.Day != tomorrow || g.Challenges["en"][0].Author != "Plato" {
+		t.Errorf("rolled over to %s: %+v", g.Day, g.Challenges)
+	}
+	if w := do(g.Postretirequote, "POST", "/", "", id); w.Code != http.StatusOK {
+		t.Errorf("retire: %d", w.Code)
+	}
+	if w := do(g.Postschedulequote, "POST", "/", `{"date": "`+tomorrow+`"}`, id); w.Code != http.StatusConflict {
+		t.Errorf("scheduled a retired challenge: %d", w.Code)
+	}
+}
@@ -0,0 +1,217 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/packs"
+	"github.com/kirtansoni/words-weave/internal/quality"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// quotes listed per page of GET /admin/challenges
+	ADMIN_PAGE_SIZE = 100
+)
+
+// quoteResponse is a quote along with how its starting content fares, the
+// report is left out when the content can't be checked.
+type quoteResponse struct {
+	Quote  db.Quote        `json:"quote"`
+	Report *quality.Report `json:"report,omitempty"`
+}
+
+// GET /admin/challenges?status=draft&day=YYYY-MM-DD&offset=0
+func (g *Game) Getquotes(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Challenges Not Available", http.StatusNotFound)
+		return
+	}
+	queryParams := r.URL.Query()
+	offset, _ := strconv.Atoi(queryParams.Get("offset"))
+	quotes, err := db.ListQuotes(g.DB, db.QuoteFilter{
+		Status: queryParams.Get("status"),
+		Date:   queryParams.Get("day"),
+		Limit:  ADMIN_PAGE_SIZE,
+		Offset: max(offset, 0),
+	})
+	if err != nil {
+		log.Printf("Listing challenges failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quotes)
+}
+
+// POST /admin/challenges {"quote": "...", "author": "...", "content": "..."}
+// A challenge with starting content is a draft until it is scheduled,
+// without one it waits for the generator.
+func (g *Game) Postquote(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Challenges Not Available", http.StatusNotFound)
+		return
+	}
+	var q db.Quote
+	if !decodeQuote(w, r, &q) {
+		return
+	}
+	q.ID, q.Date, q.Ordinal = 0, "", 0
+	q.Status = db.QUOTE_NEW
+	if q.Content != "" {
+		q.Status = db.QUOTE_DRAFT
+	}
+	id, err := db.AddQuote(g.DB, q)
+	if err != nil {
+		log.Printf("Adding challenge failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	g.writeQuote(w, id, http.StatusCreated)
+}
+
+// PATCH /admin/challenges/{id}
+// Fields left out of the body keep their value, a challenge that was
+// played already can't change.
+func (g *Game) Patchquote(w http.ResponseWriter, r *http.Request) {
+	q, ok := g.pathQuote(w, r)
+	if !ok {
+		return
+	}
+	id := q.ID
+	if !decodeQuote(w, r, &q) {
+		return
+	}
+	q.ID = id
+	err := db.UpdateQuote(g.DB, q, db.Today(time.Now()))
+	if writeQuoteError(w, err) {
+		return
+	}
+	g.writeQuote(w, id, http.StatusOK)
+}
+
+// POST /admin/challenges/{id}/schedule {"date": "YYYY-MM-DD"}
+// Puts a challenge after the ones the day already has. Today's challenges
+// are loaded at the next rollover.
+func (g *Game) Postschedulequote(w http.ResponseWriter, r *http.Request) {
+	q, ok := g.pathQuote(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Date string `json:"date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse(db.DAY_FORMAT, req.Date); err != nil {
+		http.Error(w, "Days are YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	_, err := db.ScheduleQuote(g.DB, q.ID, req.Date, db.Today(time.Now()), MAXCHALLENGES+1)
+	if writeQuoteError(w, err) {
+		return
+	}
+	g.writeQuote(w, q.ID, http.StatusOK)
+}
+
+// POST /admin/challenges/{id}/retire
+// The challenge is never scheduled again and leaves the days that haven't
+// started, past days keep it.
+func (g *Game) Postretirequote(w http.ResponseWriter, r *http.Request) {
+	q, ok := g.pathQuote(w, r)
+	if !ok {
+		return
+	}
+	err := db.RetireQuote(g.DB, q.ID, db.Today(time.Now()))
+	if writeQuoteError(w, err) {
+		return
+	}
+	g.writeQuote(w, q.ID, http.StatusOK)
+}
+
+// pathQuote loads the quote of the {id} in the path, it writes the error
+// when there is none.
+func (g *Game) pathQuote(w http.ResponseWriter, r *http.Request) (db.Quote, bool) {
+	if g.DB == nil {
+		http.Error(w, "Challenges Not Available", http.StatusNotFound)
+		return db.Quote{}, false
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid challenge", http.StatusBadRequest)
+		return db.Quote{}, false
+	}
+	q, err := db.GetQuote(g.DB, id)
+	if writeQuoteError(w, err) {
+		return q, false
+	}
+	return q, true
+}
+
+// decodeQuote reads the fields of a challenge from the body over q, the
+// words follow the quote.
+func decodeQuote(w http.ResponseWriter, r *http.Request, q *db.Quote) bool {
+	if err := json.NewDecoder(r.Body).Decode(q); err != nil {
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return false
+	}
+	q.Quote = strings.TrimSpace(q.Quote)
+	if q.Quote == "" {
+		http.Error(w, "Quote is required", http.StatusBadRequest)
+		return false
+	}
+	switch q.Difficulty {
+	case "", packs.EASY, packs.MEDIUM, packs.HARD:
+	default:
+		http.Error(w, "Unknown difficulty", http.StatusBadRequest)
+		return false
+	}
+	q.Words = tokenizer.Words(q.Tokens())
+	return true
+}
+
+// writeQuoteError answers the errors of the quotes table, it is false when
+// there was none.
+func writeQuoteError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, db.ErrNoQuote):
+		http.Error(w, "No such challenge", http.StatusNotFound)
+	case errors.Is(err, db.ErrQuotePlayed), errors.Is(err, db.ErrRetired), errors.Is(err, db.ErrNoContent),
+		errors.Is(err, db.ErrPastDay), errors.Is(err, db.ErrSlotTaken), errors.Is(err, db.ErrDayFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Updating challenge failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+	return true
+}
+
+// writeQuote answers with the stored quote and its content checks.
+func (g *Game) writeQuote(w http.ResponseWriter, id int, status int) {
+	q, err := db.GetQuote(g.DB, id)
+	if writeQuoteError(w, err) {
+		return
+	}
+	res := quoteResponse{Quote: q}
+	if q.Content != "" {
+		report, err := quality.Validator{Stemmer: g.Stemmer, Graph: g.Associations}.Validate(q.Challenge)
+		if err != nil {
+			log.Printf("Checking challenge %d failed: %v", id, err)
+		} else {
+			res.Report = &report
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
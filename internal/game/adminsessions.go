@@ -0,0 +1,155 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/analytics"
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+// sessionSummary is a line of GET /admin/sessions.
+type sessionSummary struct {
+	Key          string    `json:"key"`
+	Player       string    `json:"player"`
+	Day          string    `json:"day"`
+	Practice     bool      `json:"practice"`
+	Language     string    `json:"language"`
+	Mode         string    `json:"mode"`
+	Challenge    int       `json:"challenge"`
+	Attempts     int       `json:"attempts"`
+	Solved       bool      `json:"solved"`
+	LastAccessed time.Time `json:"lastAccessed"`
+	Active       bool      `json:"active"`
+}
+
+// GET /admin/sessions?active=1
+// Sessions in memory, most recently played first.
+func (g *Game) Getsessions(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("active") != ""
+	summaries := []sessionSummary{}
+	for key, state := range g.SessionManager.Sessions() {
+		if activeOnly && !state.IsActive() {
+			continue
+		}
+		summaries = append(summaries, sessionSummary{
+			Key:          key,
+			Player:       state.Player,
+			Day:          state.Day,
+			Practice:     state.Practice,
+			Language:     state.Language,
+			Mode:         GetMode(state.Mode).Name,
+			Challenge:    state.Challenge,
+			Attempts:     state.Attempts,
+			Solved:       state.Solved(),
+			LastAccessed: state.LastAccessed,
+			Active:       state.IsActive(),
+		})
+	}
+	slices.SortFunc(summaries, func(a, b sessionSummary) int { return b.LastAccessed.Compare(a.LastAccessed) })
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// GET /admin/sessions/{key}
+// The whole state of a session along with every attempt it logged, failed
+// ones too.
+func (g *Game) Getsession(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	state, exists := g.SessionManager.GetState(key)
+	if !exists {
+		http.Error(w, "No such session", http.StatusNotFound)
+		return
+	}
+	res := struct {
+		State   *m.State          `json:"state"`
+		History []analytics.Event `json:"history"`
+	}{State: state, History: []analytics.Event{}}
+	if g.Events != nil {
+		history, err := g.Events.History(key)
+		if err != nil {
+			log.Printf("Loading history of %s failed: %v", key, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		res.History = history
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// DELETE /admin/sessions/{key}
+// The player starts the day over on their next request, stats and the
+// attempt log stay.
+func (g *Game) Deletesession(w http.ResponseWriter, r *http.Request) {
+	if !g.SessionManager.DeleteState(r.PathValue("key")) {
+		http.Error(w, "No such session", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/live
+func (g *Game) Getlive(w http.ResponseWriter, r *http.Request) {
+	res := struct {
+		Day      string `json:"day"`
+		Active   int    `json:"activePlayers"`
+		Sessions int    `json:"sessions"`
+		// active players by language
+		Languages map[string]int `json:"languages"`
+	}{Day: g.Day, Active: g.SessionManager.ActivePlayers(), Languages: make(map[string]int)}
+	sessions := g.SessionManager.Sessions()
+	res.Sessions = len(sessions)
+	for _, state := range sessions {
+		if state.IsActive() {
+			res.Languages[state.Language]++
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// POST /admin/rollover {"date": "YYYY-MM-DD"}
+// Loads a day's challenges now instead of at midnight, today's without a
+// date. Sessions of the old day start over on their next request.
+func (g *Game) Postrollover(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Rollover Not Available", http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Date string `json:"date"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+	}
+	now := time.Now()
+	if req.Date != "" {
+		day, err := time.Parse(db.DAY_FORMAT, req.Date)
+		if err != nil {
+			http.Error(w, "Days are YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		now = day
+	}
+	if err := g.LoadDay(now); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	counts := make(map[string]int)
+	g.SessionManager.RLock()
+	for language, challenges := range g.Challenges {
+		counts[language] = len(challenges)
+	}
+	g.SessionManager.RUnlock()
+	log.Printf("Rolled over to %s", g.Day)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"day": g.Day, "challenges": counts})
+}
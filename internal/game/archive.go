@@ -0,0 +1,125 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+var (
+	ARCHIVE_PAGE   = 30
+	ErrNoArchive   = errors.New("Day Not Archived")
+	ErrNoChallenge = errors.New("Challenge Not Available")
+)
+
+// practiceDay is the archived day a request asks to replay with its `day`
+// param, empty for today's challenges.
+func (g *Game) practiceDay(r *http.Request) (string, error) {
+	day := r.URL.Query().Get("day")
+	if day == "" || day == g.Day {
+		return "", nil
+	}
+	if g.DB == nil {
+		return "", ErrNoArchive
+	}
+	if _, err := time.Parse(db.DAY_FORMAT, day); err != nil || day > db.Today(time.Now()) {
+		return "", ErrNoArchive
+	}
+	if _, err := g.challengeSet(day); err != nil {
+		return "", err
+	}
+	return day, nil
+}
+
+// stateKey is where the session keeps its state for the request, practice
+// on each archived day is kept apart from the daily game.
+func (g *Game) stateKey(sessionID string, r *http.Request) (string, error) {
+	day, err := g.practiceDay(r)
+	if err != nil || day == "" {
+		return sessionID, err
+	}
+	return sessionID + "@" + day, nil
+}
+
+// challengeSet is the challenges of an archived day by language, today's
+// for an empty day. Archived days are loaded once.
+func (g *Game) challengeSet(day string) (map[string][]m.Challenge, error) {
+	if day == "" {
+		return g.Challenges, nil
+	}
+	if set, ok := g.archive.Load(day); ok {
+		return set.(map[string][]m.Challenge), nil
+	}
+	challenges, err := db.GetChallenges(g.DB, day)
+	if err != nil {
+		log.Printf("Loading challenges of %s failed: %v", day, err)
+		return nil, err
+	}
+	if len(challenges) == 0 {
+		return nil, ErrNoArchive
+	}
+	set := groupByLanguage(challenges)
+	g.archive.Store(day, set)
+	return set, nil
+}
+
+// challenge is the challenge a state is playing.
+func (g *Game) challenge(state *m.State) (m.Challenge, error) {
+	if !state.Practice {
+		return g.GetChallenge(state.Language, state.Challenge)
+	}
+	set, err := g.challengeSet(state.Day)
+	if err != nil {
+		return m.Challenge{}, err
+	}
+	return pickChallenge(set, state.Language, state.Challenge)
+}
+
+// challengeError answers a request whose challenge can't be found, the
+// archived day it came from is gone when there is no such challenge left.
+func challengeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNoChallenge) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("Loading challenge failed: %v", err)
+	http.Error(w, "Challenge Gone", http.StatusGone)
+}
+
+// GET /archive?page=1, the days before today that can be replayed
+func (g *Game) Getarchive(w http.ResponseWriter, r *http.Request) {
+	if g.DB == nil {
+		http.Error(w, "Archive Not Available", http.StatusNotFound)
+		return
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	today := g.Day
+	if today == "" {
+		today = db.Today(time.Now())
+	}
+	days, err := db.ArchiveDays(g.DB, today, ARCHIVE_PAGE, (page-1)*ARCHIVE_PAGE)
+	if err != nil {
+		log.Printf("Loading archive failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(days)
+}
+
+// practiceOf is the archived day a state replays, empty for the daily game.
+func practiceOf(state *m.State) string {
+	if state.Practice {
+		return state.Day
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestPracticeIsKeptApart(t *testing.T) {
+	database, err := db.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	challenges := m.GetChallenges()
+	yesterday := db.Today(time.Now().Add(-24 * time.Hour))
+	if err := db.SaveChallenges(database, yesterday, challenges[3:5]); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveChallenges(database, db.Today(time.Now()), challenges[:3]); err != nil {
+		t.Fatal(err)
+	}
+	g := GetGame()
+	g.SetDB(database)
+	g.Init(context.Background())
+
+	get := func(url string, cookies []*http.Cookie) (m.ResponseStruct, *httptest.ResponseRecorder) {
+		r := httptest.NewRequest("GET", url, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		g.Getgamestate(w, r)
+		var res m.ResponseStruct
+		json.Unmarshal(w.Body.Bytes(), &res)
+		return res, w
+	}
+
+	daily, w := get("/game", nil)
+	cookies := w.Result().Cookies()
+	practice, _ := get("/game?day="+yesterday, cookies)
+	if daily.Practice || daily.Quote != challenges[0].Quote {
+		t.Errorf("daily %+v", daily)
+	}
+	if !practice.Practice || practice.Day != yesterday || practice.Quote != challenges[3].Quote {
+		t.Errorf("practice %+v", practice)
+	}
+	if again, _ := get("/game", cookies); again.Quote != daily.Quote {
+		t.Errorf("practice replaced the daily game: %q", again.Quote)
+	}
+
+	if _, w := get("/game?day=2001-01-01", cookies); w.Code != http.StatusNotFound {
+		t.Errorf("unscheduled day: %d", w.Code)
+	}
+
+	// a challenge the day doesn't have is answered, not a panic
+	found := false
+	for _, c := range cookies {
+		if state, ok := g.SessionManager.GetState(c.Value + "@" + yesterday); ok {
+			state.Challenge, found = 5, true
+		}
+	}
+	if !found {
+		t.Fatal("no practice state")
+	}
+	if _, w := get("/game?day="+yesterday, cookies); w.Code != http.StatusNotFound {
+		t.Errorf("missing practice challenge: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	g.Getarchive(w, httptest.NewRequest("GET", "/archive", nil))
+	var days []db.ArchiveDay
+	json.Unmarshal(w.Body.Bytes(), &days)
+	if len(days) != 1 || days[0].Date != yesterday {
+		t.Errorf("archive %+v", days)
+	}
+}
+
+func TestGetChallenge(t *testing.T) {
+	g := GetGame()
+	challenges := m.GetChallenges()
+	g.SetChallenges(challenges[5:7])
+
+	tests := []struct {
+		language string
+		index    int
+		quote    string
+		err      error
+	}{
+		{"es", 1, challenges[6].Quote, nil},
+		{"es", 2, "", ErrNoChallenge},
+		{"es", -1, "", ErrNoChallenge},
+		// neither French nor English has challenges today
+		{"fr", 0, challenges[5].Quote, nil},
+	}
+	for _, tt := range tests {
+		challenge, err := g.GetChallenge(tt.language, tt.index)
+		if !errors.Is(err, tt.err) || challenge.Quote != tt.quote {
+			t.Errorf("%s %d: got %q, %v", tt.language, tt.index, challenge.Quote, err)
+		}
+	}
+}
@@ -2,111 +2,305 @@ package game
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
-	// db "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/analytics"
+	"github.com/kirtansoni/words-weave/internal/associations"
+	db "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/leaderboard"
 	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
 	m "github.com/kirtansoni/words-weave/internal/models"
 	s "github.com/kirtansoni/words-weave/internal/sessions"
+	"github.com/kirtansoni/words-weave/internal/share"
+	"github.com/kirtansoni/words-weave/internal/stats"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
 )
 
 var (
 	LLM_TIMOUT    = 3 * time.Second
 	MAXCHALLENGES = 3
 	MAX_ATTEMPTS  = 25
+	HINT_COSTS    = map[string]m.HintCost{
+		m.HINT_LETTER: {Attempts: 1},
+		m.HINT_LEAD:   {Attempts: 2},
+		m.HINT_REVEAL: {Attempts: 3, Points: 10},
+	}
 )
 
 type Game struct {
 	SessionManager s.SessionManager
-	Challenges     []m.Challenge
+	// optional, finished sessions are saved here and challenges come from it
+	DB *sql.DB
+	// YYYY-MM-DD of the challenges loaded, empty for the built-in ones
+	Day string
+	// challenges of archived days being practiced, by day
+	archive sync.Map
+	// daily challenges by language
+	Challenges map[string][]m.Challenge
+	Stemmer    matcher.Stemmer
+	LLM        l.Provider
+	// least time between two attempts of a session, LLM_TIMOUT unless
+	// changed
+	AttemptInterval time.Duration
+	// optional, learns which input words lead to which challenge words
+	Associations *associations.Graph
+	// optional, keeps shared results for their permalinks
+	Shares *share.Store
+	// optional, lifetime stats of players
+	Stats *stats.Store
+	// optional, ranks players who opted in
+	Leaderboard *leaderboard.Board
+	// optional, every attempt for analytics
+	Events *analytics.Log
+	// bearer token of the /admin API, or basic auth as AdminUser with a
+	// password hashed by the password package. The API is off without either.
+	AdminToken    string
+	AdminUser     string
+	AdminPassword string
+	adminGuard    adminGuard
 }
 
 func GetGame() *Game {
 	game := &Game{
 		SessionManager: *s.GetSessionManger(),
+		Stemmer:        matcher.GetStemmer(),
+		LLM:            l.GetProvider(),
+		// copied so a game can change it without touching the others
+		AttemptInterval: LLM_TIMOUT,
 	}
 	return game
 }
 
+// SetDB hands the migrated database to the game and the parts of it that
+// keep their own tables.
+func (g *Game) SetDB(database *sql.DB) {
+	g.DB = database
+	g.Associations = associations.New(database)
+	g.Shares = share.NewStore(database)
+	g.Stats = stats.New(database)
+	g.Leaderboard = leaderboard.New(database)
+	g.Events = analytics.New(database)
+}
+
 func (g *Game) SetChallenges(challenges []m.Challenge) {
+	byLanguage := groupByLanguage(challenges)
 	g.SessionManager.Lock()
 	defer g.SessionManager.Unlock()
-	g.Challenges = challenges
+	g.Challenges = byLanguage
+}
+
+func groupByLanguage(challenges []m.Challenge) map[string][]m.Challenge {
+	byLanguage := make(map[string][]m.Challenge)
+	for _, challenge := range challenges {
+		language := challenge.GetLanguage()
+		byLanguage[language] = append(byLanguage[language], challenge)
+	}
+	return byLanguage
 }
+
+// Init loads today's challenges from the database and swaps them at every
+// midnight, without a database the built-in challenges are played.
 func (g *Game) Init(ctx context.Context) {
-	
-	challenges := m.GetChallenges() 
+	if g.DB == nil {
+		g.SetChallenges(m.GetChallenges())
+		return
+	}
+	if err := g.LoadDay(time.Now()); err != nil {
+		log.Printf("Loading today's challenges failed, playing the built-in ones: %v", err)
+		g.SetChallenges(m.GetChallenges())
+	}
+	go g.CronJob(ctx)
+}
+
+// LoadDay makes the challenges scheduled for the day of now the ones
+// played. A fresh database gets the built-in challenges scheduled first,
+// otherwise a day with nothing scheduled is an error and the old set stays.
+func (g *Game) LoadDay(now time.Time) error {
+	day := db.Today(now)
+	challenges, err := db.GetChallenges(g.DB, day)
+	if err != nil {
+		return err
+	}
+	if len(challenges) == 0 {
+		scheduled, err := db.HasSchedule(g.DB)
+		if err != nil {
+			return err
+		}
+		if scheduled {
+			return fmt.Errorf("no challenges scheduled for %s", day)
+		}
+		if err := db.SaveChallenges(g.DB, day, m.GetChallenges()); err != nil {
+			return err
+		}
+		return g.LoadDay(now)
+	}
 	g.SetChallenges(challenges)
-	// go g.CronJob(ctx)
+	g.SessionManager.Lock()
+	defer g.SessionManager.Unlock()
+	g.Day = day
+	return nil
+}
+
+// CronJob loads the next day's challenges at every midnight UTC.
+func (g *Game) CronJob(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		timer := time.NewTimer(midnight.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := g.LoadDay(midnight); err != nil {
+				log.Printf("Loading challenges of %s failed: %v", db.Today(midnight), err)
+			}
+		}
+	}
 }
 
+// IsValidState is false for states of a day that is over, practice on an
+// archived day never gets stale.
 func (g *Game) IsValidState(state *m.State) bool {
-	// today := time.Now().Truncate(24 * time.Hour)
-	// lastAccessed := state.LastAccessed.Truncate(24 * time.Hour)
-	// if !lastAccessed.Equal(today) {
-	// 	return false
-	// }
-	return true
+	return g.Day == "" || state.Practice || state.Day == g.Day
 }
 
-func (g *Game) NewState(sessionid string, challenge int) *m.State {
-	return &m.State{
+// NewState starts the day's challenges, practice is an archived day's and
+// today's if it is empty.
+func (g *Game) NewState(sessionid string, practice string, language string, mode string, challenge int) *m.State {
+	day := g.Day
+	if practice != "" {
+		day = practice
+	}
+	state := &m.State{
 		ID:           sessionid,
 		Challenge:    challenge,
-		Progress:     make([]bool, len(g.GetChallengeWords(challenge))),
-		Content:      make([]m.Entry, MAX_ATTEMPTS),
+		Day:          day,
+		Practice:     practice != "",
+		Language:     language,
+		Mode:         GetMode(mode).Name,
+		Started:      time.Now(),
+		Content:      make([]m.Entry, 0, MAX_ATTEMPTS),
+		Head:         -1,
 		Attempts:     0,
 		LastAccessed: time.Now(),
 	}
+	// without a challenge the handlers answer before the state is played
+	if c, err := g.challenge(state); err == nil {
+		state.Language = c.GetLanguage()
+		state.Progress = c.Given()
+	}
+	return state
 }
 
 func (g *Game) isComplete(state *m.State) bool {
-	if state.Attempts >= MAX_ATTEMPTS {
+	if state.Solved() {
 		return true
 	}
-	for i := range state.Progress {
-		if !state.Progress[i] {
-			return false
+	mode := GetMode(state.Mode)
+	return mode.OutOfAttempts(state) || mode.OutOfTime(state, time.Now())
+}
+
+// finish stamps the time a challenge was solved or ran out of attempts,
+// and counts it in the player's stats and on the leaderboards.
+func (g *Game) finish(state *m.State) {
+	if !state.Finished.IsZero() || !g.isComplete(state) {
+		return
+	}
+	state.Finished = time.Now()
+	if state.Player == "" {
+		return
+	}
+	result := g.result(state)
+	if g.Stats != nil {
+		err := g.Stats.Record(state.Player, stats.Location(state.Timezone), state.Language, state.Day, result, state.Finished)
+		if err != nil {
+			log.Printf("Recording stats failed: %v", err)
+		}
+	}
+	if g.Leaderboard != nil {
+		err := g.Leaderboard.Record(state.Player, state.Language, state.Day, result, GetMode(state.Mode), state.Started, state.Finished)
+		if err != nil {
+			log.Printf("Recording leaderboard failed: %v", err)
 		}
 	}
-	return true
 }
 
 func (g *Game) setNextState(state *m.State) error {
-	if state.Challenge >= MAXCHALLENGES {
+	set, err := g.challengeSet(practiceOf(state))
+	if err != nil {
+		return err
+	}
+	if state.Challenge >= MAXCHALLENGES || state.Challenge+1 >= len(set[state.Language]) {
 		return errors.New("No more challenges allowed for the day")
 	}
+	state.Results = append(state.Results, g.result(state))
 	state.Challenge++
 	state.Attempts = 0
-	state.Content = make([]m.Entry, MAX_ATTEMPTS)
-	state.Progress = make([]bool, len(g.GetChallengeWords(state.Challenge)))
+	state.Hints = nil
+	state.Started = time.Now()
+	state.Finished = time.Time{}
+	state.Content = make([]m.Entry, 0, MAX_ATTEMPTS)
+	state.Head = -1
+	challenge, err := g.challenge(state)
+	if err != nil {
+		return err
+	}
+	state.Progress = challenge.Given()
 	return nil
 
 }
 
 func (g *Game) Getgamestate(w http.ResponseWriter, r *http.Request) {
 
+	queryParams := r.URL.Query()
+	practice, err := g.practiceDay(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	set, _ := g.challengeSet(practice)
+	language := queryParams.Get("lang")
+	if _, ok := set[language]; !ok {
+		language = m.DEFAULT_LANGUAGE
+	}
+	mode := queryParams.Get("mode")
+	if !slices.Contains(MODE_NAMES, mode) {
+		mode = ""
+	}
+
 	//get session id from cookie
 	sessionID, err := g.SessionManager.GetSessionID(r)
 	if err != nil || sessionID == "" {
 		//if not present create a new session ID and set it to the cookie
 		sessionID = g.SessionManager.SetSessionID(w)
-		g.SessionManager.SetState(sessionID, g.NewState(sessionID, 0))
 	}
+	key, _ := g.stateKey(sessionID, r)
 
-	state, exists := g.SessionManager.GetState(sessionID)
+	state, exists := g.SessionManager.GetState(key)
 	//get state for the session ID
 	if !exists {
-		//should not happen
-		g.SessionManager.SetState(sessionID, g.NewState(sessionID, 0))
+		g.SessionManager.SetState(key, g.NewState(sessionID, practice, language, mode, 0))
+	}
+
+	//switching languages starts that language's challenges
+	if exists && queryParams.Get("lang") != "" && state.Language != language {
+		state = g.NewState(sessionID, practice, language, mode, 0)
+		g.SessionManager.SetState(key, state)
 	}
 
-	queryParams := r.URL.Query()
 	next := queryParams.Get("next")
 	//get next state if eligible
 	if next != "" && exists && g.isComplete(state) {
@@ -121,21 +315,29 @@ func (g *Game) Getgamestate(w http.ResponseWriter, r *http.Request) {
 
 	}
 
-	if !g.IsValidState(state) || state == nil {
-		//initialize state if it doesnt exist or is stale
-		state = g.NewState(sessionID, 0)
-		g.SessionManager.SetState(sessionID, state)
+	//the mode is picked when a challenge starts, before any attempt. The
+	//clock keeps running from when the challenge was first served
+	if mode != "" && exists && state.Attempts == 0 && len(state.Hints) == 0 && state.Mode != mode {
+		state.Mode = mode
 	}
 
-	//get the payload for the session state
-	res := state.GetPayload()
-	res.Quote = g.Challenges[state.Challenge].Quote
-	res.Author = g.Challenges[state.Challenge].Author
-	if state.Attempts == 0 {
-		res.Content = g.Challenges[state.Challenge].Content
+	if state == nil || !g.IsValidState(state) {
+		//initialize state if it doesnt exist or is stale
+		state = g.NewState(sessionID, practice, language, mode, 0)
+		g.SessionManager.SetState(key, state)
 	}
+	if _, err := g.challenge(state); err != nil {
+		challengeError(w, err)
+		return
+	}
+	state.Player = g.SessionManager.GetPlayerID(w, r)
+	if tz := queryParams.Get("tz"); tz != "" && stats.Location(tz) != time.UTC {
+		state.Timezone = tz
+	}
+	//timed challenges run out without any request
+	g.finish(state)
 
-	payload, err := json.Marshal(res)
+	payload, err := json.Marshal(g.payload(state))
 	if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -153,7 +355,12 @@ func (g *Game) Postgamestate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s, exists := g.SessionManager.GetState(sessionID)
+	key, err := g.stateKey(sessionID, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s, exists := g.SessionManager.GetState(key)
 	//get state for the session ID
 	if !exists {
 		//should not happen
@@ -161,7 +368,7 @@ func (g *Game) Postgamestate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if time.Since(s.LastAccessed) < LLM_TIMOUT {
+	if time.Since(s.LastAccessed) < g.AttemptInterval {
 		http.Error(w, "Too many Requests", http.StatusRequestTimeout)
 		return
 	}
@@ -192,24 +399,63 @@ func (g *Game) Postgamestate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	challenge, err := g.challenge(s)
+	if err != nil {
+		challengeError(w, err)
+		return
+	}
 	if g.isComplete(s) {
+		g.finish(s)
 		w.WriteHeader(http.StatusExpectationFailed)
 		return
 	}
 
+	if !s.Validate(challenge, req.Input) {
+		http.Error(w, "Input must use words of the passage", http.StatusBadRequest)
+		return
+	}
+	if GetMode(s.Mode).NoTargetInput {
+		used, err := g.usesTargetWords(challenge, req.Input)
+		if err != nil {
+			log.Printf("Matcher unavailable: %v", err)
+			http.Error(w, "Session Could not update, check microservice code", http.StatusBadRequest)
+			return
+		}
+		if used {
+			http.Error(w, "Challenge words can't be picked in this mode", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// match challenge words while the passage streams in
+	mt, err := matcher.New(g.Stemmer, challenge, s.Progress)
+	if err != nil {
+		log.Printf("Matcher unavailable: %v", err)
+		http.Error(w, "Session Could not update, check microservice code", http.StatusBadRequest)
+		return
+	}
+	events := r.URL.Query().Get("events") != ""
+	var passage strings.Builder
+	var matchError error
+
+	// an attempt is what makes a player active, and what AttemptInterval spaces out
+	s.LastAccessed = time.Now()
 	chunks := make(chan string, 10)
-	var content string
-	var streamError error
+	streamErrors := make(chan error, 1)
+	started := time.Now()
+	progress := slices.Clone(s.Progress)
 
 	// CRITICAL FIX: Handle goroutine panics and errors
 	go func() {
+		var err error
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("Recovered from panic in StreamingLLM goroutine: %v", r)
-				streamError = fmt.Errorf("streaming failed due to panic: %v", r)
+				err = fmt.Errorf("streaming failed due to panic: %v", r)
 			}
+			streamErrors <- err
 		}()
-		content = l.StreamingLLM(req.Input, r.Context(), chunks)
+		_, err = g.LLM.Stream(r.Context(), req.Input, s.Language, chunks)
 	}()
 
 	for {
@@ -217,19 +463,49 @@ func (g *Game) Postgamestate(w http.ResponseWriter, r *http.Request) {
 		case chunk, ok := <-chunks:
 			if !ok {
 				// Channel closed, streaming is done
-				if streamError != nil {
+				if streamError := <-streamErrors; streamError != nil {
+					g.logAttempt(s, key, challenge, req.Input, passage.String(), nil, started, analytics.Classify(streamError, false))
 					http.Error(w, "Streaming failed", http.StatusInternalServerError)
 					return
 				}
-				
-				// update session after streaming is over
-				err := s.UpdateSession(req.Input, content, g.GetChallengeWords(s.Challenge))
-				if err != nil {
+				if matchError == nil {
+					var matched []int
+					matched, matchError = mt.Close()
+					near := mt.TakeNearMisses()
+					if events && (len(matched) > 0 || len(near) > 0) {
+						writeEvent(w, m.StreamEvent{Matched: matched, Close: near})
+					}
+				}
+				if matchError != nil {
+					g.logAttempt(s, key, challenge, req.Input, passage.String(), nil, started, analytics.Classify(matchError, true))
+					log.Printf("Matching failed: %v", matchError)
 					http.Error(w, "Session Could not update, check microservice code", http.StatusBadRequest)
 					return
 				}
-				if g.isComplete(s) {
-					// db.SaveState(s)
+
+				// update session after streaming is over
+				s.UpdateSession(req.Input, passage.String(), mt.Found(), mt.Matches(), mt.NearMisses())
+				var unlocked []int
+				for i := range progress {
+					if !progress[i] && s.Progress[i] {
+						unlocked = append(unlocked, i)
+					}
+				}
+				g.logAttempt(s, key, challenge, req.Input, passage.String(), unlocked, started, "")
+				if g.Associations != nil {
+					err := g.Associations.Record(challenge, *s.Current())
+					if err != nil {
+						log.Printf("Recording associations failed: %v", err)
+					}
+				}
+				g.finish(s)
+				if events {
+					writeEvent(w, m.StreamEvent{Progress: s.Progress, Matches: mt.Matches(), Close: mt.NearMisses()})
+				}
+				if g.isComplete(s) && g.DB != nil {
+					if err := db.SaveState(g.DB, s); err != nil {
+						log.Printf("Saving session failed: %v", err)
+					}
 				}
 				return
 			} else {
@@ -238,91 +514,162 @@ func (g *Game) Postgamestate(w http.ResponseWriter, r *http.Request) {
 					http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 					return
 				}
+				passage.WriteString(chunk)
+				var matched []int
+				if matchError == nil {
+					matched, matchError = mt.Write(chunk)
+				}
 				//stream chunks
-				fmt.Fprint(w, chunk)
+				if events {
+					writeEvent(w, m.StreamEvent{Chunk: chunk, Matched: matched, Close: mt.TakeNearMisses()})
+				} else {
+					fmt.Fprint(w, chunk)
+				}
 				flusher.Flush()
 			}
 		case <-r.Context().Done():
 			// Client disconnected
 			log.Println("Client disconnected during streaming")
+			g.logAttempt(s, key, challenge, req.Input, passage.String(), nil, started, analytics.ERROR_DISCONNECTED)
 			return
 		}
 	}
 }
 
-func (g *Game) GetChallengeWords(index int) []string {
-	if index > MAXCHALLENGES {
-		panic("GetChallenge > MAXCHALLENGES")
+// Postrewind moves the player back to an earlier passage of the attempt
+// tree, the next attempt branches off from there.
+func (g *Game) Postrewind(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := g.SessionManager.GetSessionID(r)
+	if err != nil || sessionID == "" {
+		http.Error(w, "No Session Detected", http.StatusRequestTimeout)
+		return
+	}
+	key, err := g.stateKey(sessionID, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s, exists := g.SessionManager.GetState(key)
+	if !exists || !g.IsValidState(s) {
+		http.Error(w, "Invalid Session", http.StatusUnauthorized)
+		return
 	}
-	return g.Challenges[index].Words
+
+	var req struct {
+		Entry int `json:"entry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if g.isComplete(s) {
+		w.WriteHeader(http.StatusExpectationFailed)
+		return
+	}
+	if err := s.Rewind(req.Entry); err != nil {
+		http.Error(w, "Invalid entry", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(g.payload(s))
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Write(payload)
+}
+
+// payload is the session state along with its challenge.
+func (g *Game) payload(state *m.State) m.ResponseStruct {
+	res := state.GetPayload()
+	challenge, _ := g.challenge(state)
+	res.Quote = challenge.Quote
+	res.Words = challenge.Tokens()
+	res.Author = challenge.Author
+	res.Given = challenge.Given()
+	mode := GetMode(state.Mode)
+	res.Mode = mode
+	res.AttemptsLeft = mode.AttemptsLeft(state)
+	if deadline := mode.Deadline(state); !deadline.IsZero() {
+		res.Deadline = &deadline
+	}
+	res.Score = mode.Score(challenge, state)
+	res.Content = g.currentPassage(state)
+	return res
+}
+
+// currentPassage is the passage the player picks words from next.
+func (g *Game) currentPassage(state *m.State) string {
+	if current := state.Current(); current != nil {
+		return current.Content
+	}
+	challenge, _ := g.challenge(state)
+	return challenge.Content
+}
+
+// logAttempt appends an attempt to the analytics log. Attempts that went
+// through were already added to the state, failed ones never will be.
+func (g *Game) logAttempt(s *m.State, key string, challenge m.Challenge, input, output string, unlocked []int, started time.Time, errorClass string) {
+	if g.Events == nil {
+		return
+	}
+	attempt, parent := s.Attempts+1, s.Head
+	if current := s.Current(); errorClass == "" && current != nil {
+		attempt, parent = s.Attempts, current.Parent
+	}
+	err := g.Events.Record(analytics.Event{
+		Session:       key,
+		Day:           s.Day,
+		Practice:      s.Practice,
+		Language:      s.Language,
+		Challenge:     s.Challenge,
+		Attempt:       attempt,
+		Parent:        parent,
+		Input:         tokenizer.Words(tokenizer.Tokenize(input, challenge.TokenizerOptions())),
+		Output:        output,
+		Matched:       unlocked,
+		Latency:       time.Since(started),
+		Model:         g.LLM.Model(),
+		PromptVersion: l.PROMPT_VERSION,
+		CacheHit:      false,
+		ErrorClass:    errorClass,
+	})
+	if err != nil {
+		log.Printf("Logging attempt failed: %v", err)
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event m.StreamEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	w.Write(append(line, '\n'))
+}
+
+// GetChallenge is today's challenge at index in language, see pickChallenge.
+func (g *Game) GetChallenge(language string, index int) (m.Challenge, error) {
+	return pickChallenge(g.Challenges, language, index)
 }
 
-// func (g *Game) CronJob(ctx context.Context) {
-
-// 	now := time.Now()
-// 	nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-// 	durationUntilMidnight := time.Until(nextMidnight)
-// 	time.Sleep(durationUntilMidnight)
-// 	g.MidNightUpdate(ctx)
-// 	// After running once, set up the ticker to run every 24 hours.
-// 	ticker := time.NewTicker(24 * time.Hour)
-// 	defer ticker.Stop()
-
-// 	// Keep running the task every 24 hours.
-// 	for range ticker.C {
-// 		g.MidNightUpdate(ctx)
-// 	}
-
-// }
-
-// func (g *Game) MidNightUpdate(ctx context.Context) {
-// 	log.Println("Running scheduled task at midnight")
-
-// 	// g.SessionManager.SaveAllSessionsToDB()
-// 	// g.SessionManager.ClearAllSessions()
-
-// 	newChallenges := APIChallenges(MAXCHALLENGES, ctx) // Fetch new challenges
-// 	g.SetChallenges(newChallenges)
-// }
-
-// func APIChallenges(s int, ctx context.Context) []m.Challenge {
-// 	resp, err := http.Get("https://zenquotes.io/api/quotes")
-// 	if err != nil {
-// 		log.Println("unable to fetch qoutes from zenquotes")
-// 		return nil
-// 	}
-// 	defer resp.Body.Close()
-
-// 	body, err := io.ReadAll(resp.Body)
-// 	if err != nil {
-// 		return nil
-// 	}
-// 	type tempQuote struct {
-// 		Quote  string `json:"q"`
-// 		Author string `json:"a"`
-// 	}
-// 	var rawQuotes []tempQuote
-// 	fmt.Println(body)
-// 	// Parse JSON
-// 	if err := json.Unmarshal(body, &rawQuotes); err != nil {
-// 		log.Println(err)
-// 	}
-// 	fmt.Println("Number of rawQoutes:", len(rawQuotes))
-
-// 	var res []m.Challenge = make([]m.Challenge, s)
-
-// 	// summaries := l.LLMSummaries(s, ctx)
-// 	// fmt.Println("Number of summaries:", len(summaries))
-
-// 	for i := 0; i < len(res); i++ {
-// 		content := "The honeybee, an insect known for its role in pollination, has a highly organized social structure within the hive, which can house up to 60,000 bees. At its core is the queen, whose primary function is reproduction, laying up to 2,000 eggs per day. Worker bees, all female, engage in various roles based on age, from caring for larvae to foraging for nectar. Remarkably, the structure of a honeycomb is composed of perfect hexagons, a shape that allows for maximum efficiency in storing honey and larvae. Moreover, honeybees communicate through complex \"waggle dances,\" conveying information about the direction and distance of food sources to other hive members."
-// 		words := m.SanitizeAndSplit(rawQuotes[i].Quote)
-// 		res[i] = m.Challenge{
-// 			Quote:   rawQuotes[i].Quote,
-// 			Author:  rawQuotes[i].Author,
-// 			Content: content,
-// 			Words:   words,
-// 		}
-// 	}
-// 	return res
-// }
+// pickChallenge is the challenge at index in language. A language without
+// challenges falls back to DEFAULT_LANGUAGE, then to any language that has
+// some.
+func pickChallenge(set map[string][]m.Challenge, language string, index int) (m.Challenge, error) {
+	if len(set[language]) == 0 {
+		language = m.DEFAULT_LANGUAGE
+	}
+	if len(set[language]) == 0 {
+		for _, other := range slices.Sorted(maps.Keys(set)) {
+			if len(set[other]) > 0 {
+				language = other
+				break
+			}
+		}
+	}
+	challenges := set[language]
+	if index < 0 || index > MAXCHALLENGES || index >= len(challenges) {
+		return m.Challenge{}, ErrNoChallenge
+	}
+	return challenges[index], nil
+}
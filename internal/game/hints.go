@@ -0,0 +1,181 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/kirtansoni/words-weave/internal/analytics"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/nearmiss"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// passage words suggested by a lead hint
+	MAX_LEADS = 3
+)
+
+func (g *Game) Posthint(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := g.SessionManager.GetSessionID(r)
+	if err != nil || sessionID == "" {
+		http.Error(w, "No Session Detected", http.StatusRequestTimeout)
+		return
+	}
+	key, err := g.stateKey(sessionID, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s, exists := g.SessionManager.GetState(key)
+	if !exists || !g.IsValidState(s) {
+		http.Error(w, "Invalid Session", http.StatusUnauthorized)
+		return
+	}
+	challenge, err := g.challenge(s)
+	if err != nil {
+		challengeError(w, err)
+		return
+	}
+
+	var req struct {
+		Target int    `json:"target"`
+		Tier   string `json:"tier"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	cost, ok := HINT_COSTS[req.Tier]
+	if !ok {
+		http.Error(w, "Unknown hint tier", http.StatusBadRequest)
+		return
+	}
+	if req.Target < 0 || req.Target >= len(s.Progress) {
+		http.Error(w, "Invalid target word", http.StatusBadRequest)
+		return
+	}
+	if s.Progress[req.Target] {
+		http.Error(w, "Word already found", http.StatusBadRequest)
+		return
+	}
+	if left := GetMode(s.Mode).AttemptsLeft(s); g.isComplete(s) || (left >= 0 && cost.Attempts > left) {
+		http.Error(w, "Not enough attempts left", http.StatusExpectationFailed)
+		return
+	}
+
+	res := m.HintResponse{
+		Target: req.Target,
+		Tier:   req.Tier,
+		Cost:   cost,
+	}
+	switch req.Tier {
+	case m.HINT_LETTER:
+		res.Letter = string([]rune(challenge.Words[req.Target])[:1])
+	case m.HINT_LEAD:
+		leads, err := g.leads(challenge, g.currentPassage(s), s.Progress, req.Target)
+		if err != nil {
+			log.Printf("Lead hint failed: %v", err)
+			http.Error(w, "Hint not available", http.StatusServiceUnavailable)
+			return
+		}
+		if len(leads) == 0 {
+			// nothing to suggest, the hint is not charged
+			http.Error(w, "No lead in this passage", http.StatusNotFound)
+			return
+		}
+		res.Leads = leads
+	case m.HINT_REVEAL:
+		// the challenge word, an expanded "Don't" reveals "do" and "not"
+		res.Word = challenge.Words[req.Target]
+	}
+
+	s.SpendHint(req.Target, req.Tier, cost)
+	g.logHint(s, key, req.Target, req.Tier)
+	g.finish(s)
+	res.Attempts = s.Attempts
+	res.Progress = s.Progress
+
+	payload, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Write(payload)
+}
+
+// logHint appends a hint just taken to the analytics log, a revealed word
+// is found by it.
+func (g *Game) logHint(s *m.State, key string, target int, tier string) {
+	if g.Events == nil {
+		return
+	}
+	var matched []int
+	if tier == m.HINT_REVEAL {
+		matched = []int{target}
+	}
+	err := g.Events.Record(analytics.Event{
+		Session:   key,
+		Day:       s.Day,
+		Practice:  s.Practice,
+		Language:  s.Language,
+		Challenge: s.Challenge,
+		Attempt:   s.Attempts,
+		Parent:    s.Head,
+		Matched:   matched,
+		Hint:      tier,
+	})
+	if err != nil {
+		log.Printf("Logging hint failed: %v", err)
+	}
+}
+
+// leads ranks the words of the passage that led to the target word before,
+// then the ones close to it, thesaurus relations first.
+func (g *Game) leads(challenge m.Challenge, passage string, progress []bool, target int) ([]string, error) {
+	var leads []string
+	if g.Associations != nil {
+		suggestions, err := g.Associations.Rank(challenge, passage, challenge.Words[target:target+1], MAX_LEADS)
+		if err != nil {
+			return nil, err
+		}
+		for _, suggestion := range suggestions {
+			leads = append(leads, suggestion.Word)
+		}
+	}
+
+	stems, err := matcher.StemChallenge(g.Stemmer, challenge)
+	if err != nil {
+		return nil, err
+	}
+	detector := nearmiss.New(challenge, stems)
+	rank := map[string]int{
+		nearmiss.REASON_RELATED:  0,
+		nearmiss.REASON_PREFIX:   1,
+		nearmiss.REASON_SPELLING: 2,
+	}
+
+	var misses []m.NearMiss
+	for _, word := range tokenizer.Words(tokenizer.Tokenize(passage, challenge.TokenizerOptions())) {
+		for _, miss := range detector.Check(word, "", progress) {
+			if miss.Target == target && !slices.Contains(misses, miss) {
+				misses = append(misses, miss)
+			}
+		}
+	}
+	slices.SortStableFunc(misses, func(a, b m.NearMiss) int {
+		return rank[a.Reason] - rank[b.Reason]
+	})
+
+	for _, miss := range misses {
+		if len(leads) == MAX_LEADS {
+			break
+		}
+		if !slices.Contains(leads, miss.Word) {
+			leads = append(leads, miss.Word)
+		}
+	}
+	return leads, nil
+}
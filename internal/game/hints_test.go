@@ -0,0 +1,137 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestPosthint(t *testing.T) {
+	g := GetGame()
+	g.Stemmer = matcher.LocalStemmer{}
+	g.SetChallenges(m.GetChallenges())
+	// "When you reach the end of your rope, tie a knot in it and hang on."
+	const rope, knot = 7, 10
+
+	tests := []struct {
+		name    string
+		tier    string
+		target  int
+		setup   func(s *m.State)
+		code    int
+		charged int
+	}{
+		{"letter", m.HINT_LETTER, rope, nil, http.StatusOK, 1},
+		{"lead", m.HINT_LEAD, rope, func(s *m.State) {
+			s.UpdateSession("rope", "A ropeway crosses the valley.", nil, nil, nil)
+		}, http.StatusOK, 2},
+		{"no lead", m.HINT_LEAD, knot, nil, http.StatusNotFound, 0},
+		{"reveal", m.HINT_REVEAL, rope, nil, http.StatusOK, 3},
+		{"unknown tier", "riddle", rope, nil, http.StatusBadRequest, 0},
+		{"bad target", m.HINT_LETTER, 99, nil, http.StatusBadRequest, 0},
+		{"already found", m.HINT_LETTER, rope, func(s *m.State) { s.Progress[rope] = true }, http.StatusBadRequest, 0},
+		{"not enough attempts", m.HINT_REVEAL, rope, func(s *m.State) { s.Attempts = MAX_ATTEMPTS - 2 }, http.StatusExpectationFailed, 0},
+		{"last attempts", m.HINT_LEAD, rope, func(s *m.State) {
+			s.UpdateSession("rope", "A ropeway crosses the valley.", nil, nil, nil)
+			s.Attempts = MAX_ATTEMPTS - 2
+		}, http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		s := g.NewState("hint-"+tt.name, "", "en", "", 0)
+		if tt.setup != nil {
+			tt.setup(s)
+		}
+		before := s.Attempts
+		g.SessionManager.SetState(s.ID, s)
+
+		body, _ := json.Marshal(map[string]any{"target": tt.target, "tier": tt.tier})
+		r := httptest.NewRequest("POST", "/game/hint", bytes.NewReader(body))
+		r.AddCookie(&http.Cookie{Name: "session", Value: s.ID})
+		w := httptest.NewRecorder()
+		g.Posthint(w, r)
+
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if charged := s.Attempts - before; charged != tt.charged {
+			t.Errorf("%s: charged %d attempts, want %d", tt.name, charged, tt.charged)
+		}
+		if w.Code != http.StatusOK {
+			if len(s.Hints) != 0 {
+				t.Errorf("%s: failed hint was recorded", tt.name)
+			}
+			continue
+		}
+		var res m.HintResponse
+		json.Unmarshal(w.Body.Bytes(), &res)
+		switch tt.tier {
+		case m.HINT_LETTER:
+			if res.Letter != "r" {
+				t.Errorf("%s: letter %q", tt.name, res.Letter)
+			}
+		case m.HINT_LEAD:
+			if !slices.Equal(res.Leads, []string{"ropeway"}) {
+				t.Errorf("%s: leads %v", tt.name, res.Leads)
+			}
+		case m.HINT_REVEAL:
+			if res.Word != "rope" || !s.Progress[rope] {
+				t.Errorf("%s: revealed %q, progress %v", tt.name, res.Word, s.Progress)
+			}
+		}
+		// spending the last attempts on a hint ends the challenge
+		if done := s.Attempts >= MAX_ATTEMPTS; done == s.Finished.IsZero() {
+			t.Errorf("%s: %d attempts, finished at %v", tt.name, s.Attempts, s.Finished)
+		}
+	}
+}
+
+func TestRevealFinishesChallenge(t *testing.T) {
+	g := GetGame()
+	g.SetChallenges(m.GetChallenges())
+	s := g.NewState("reveal", "", "en", "", 0)
+	for i := range s.Progress {
+		s.Progress[i] = i != 0
+	}
+	g.SessionManager.SetState(s.ID, s)
+
+	r := httptest.NewRequest("POST", "/game/hint", bytes.NewReader([]byte(`{"target": 0, "tier": "reveal"}`)))
+	r.AddCookie(&http.Cookie{Name: "session", Value: s.ID})
+	w := httptest.NewRecorder()
+	g.Posthint(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	if !s.Solved() || s.Finished.IsZero() {
+		t.Errorf("revealing the last word should solve the challenge: %v", s.Progress)
+	}
+	if points := s.HintPoints(); points != HINT_COSTS[m.HINT_REVEAL].Points {
+		t.Errorf("hint points %d", points)
+	}
+}
+
+func TestRevealExpandedContraction(t *testing.T) {
+	g := GetGame()
+	g.SetChallenges(m.GetChallenges())
+	// "Don't judge each day by the harvest you reap but by the seeds that you plant."
+	s := g.NewState("reveal-contraction", "", "en", "", 2)
+	g.SessionManager.SetState(s.ID, s)
+
+	for target, want := range []string{"do", "not"} {
+		body, _ := json.Marshal(map[string]any{"target": target, "tier": m.HINT_REVEAL})
+		r := httptest.NewRequest("POST", "/game/hint", bytes.NewReader(body))
+		r.AddCookie(&http.Cookie{Name: "session", Value: s.ID})
+		w := httptest.NewRecorder()
+		g.Posthint(w, r)
+		var res m.HintResponse
+		json.Unmarshal(w.Body.Bytes(), &res)
+		if w.Code != http.StatusOK || res.Word != want {
+			t.Errorf("target %d: got %d %q, want %q", target, w.Code, res.Word, want)
+		}
+	}
+}
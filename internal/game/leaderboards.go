@@ -0,0 +1,105 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/leaderboard"
+)
+
+var (
+	LEADERBOARD_PAGE     = 20
+	LEADERBOARD_MAX_PAGE = 100
+)
+
+// GET /leaderboard?board=daily|weekly|alltime&by=score|attempts&page=1&limit=20
+// The current period is shown unless `period` names another one.
+func (g *Game) Getleaderboard(w http.ResponseWriter, r *http.Request) {
+	if g.Leaderboard == nil {
+		http.Error(w, "Leaderboards Not Available", http.StatusNotFound)
+		return
+	}
+	queryParams := r.URL.Query()
+	board := queryParams.Get("board")
+	switch board {
+	case leaderboard.DAILY, leaderboard.WEEKLY, leaderboard.ALLTIME:
+	case "":
+		board = leaderboard.DAILY
+	default:
+		http.Error(w, "Unknown board", http.StatusBadRequest)
+		return
+	}
+	by := queryParams.Get("by")
+	if by != leaderboard.BY_ATTEMPTS {
+		by = leaderboard.BY_SCORE
+	}
+	period := queryParams.Get("period")
+	if period == "" {
+		period = leaderboard.Period(board, time.Now())
+	}
+	limit, err := strconv.Atoi(queryParams.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = LEADERBOARD_PAGE
+	}
+	limit = min(limit, LEADERBOARD_MAX_PAGE)
+	page, err := strconv.Atoi(queryParams.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	player := g.SessionManager.GetPlayerID(w, r)
+	res, err := g.Leaderboard.Get(board, by, period, player, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("Loading leaderboard failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+type optinRequest struct {
+	Name string `json:"name"`
+}
+
+// POST /leaderboard/optin
+func (g *Game) Postoptin(w http.ResponseWriter, r *http.Request) {
+	if g.Leaderboard == nil {
+		http.Error(w, "Leaderboards Not Available", http.StatusNotFound)
+		return
+	}
+	var req optinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	player := g.SessionManager.GetPlayerID(w, r)
+	switch err := g.Leaderboard.OptIn(player, req.Name); err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case leaderboard.ErrInvalidName:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case leaderboard.ErrNameTaken:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Leaderboard opt in failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// DELETE /leaderboard/optin
+func (g *Game) Deleteoptin(w http.ResponseWriter, r *http.Request) {
+	if g.Leaderboard == nil {
+		http.Error(w, "Leaderboards Not Available", http.StatusNotFound)
+		return
+	}
+	if err := g.Leaderboard.OptOut(g.SessionManager.GetPlayerID(w, r)); err != nil {
+		log.Printf("Leaderboard opt out failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
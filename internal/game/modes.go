@@ -0,0 +1,51 @@
+package game
+
+import (
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+const (
+	MODE_CLASSIC = "classic"
+	MODE_ZEN     = "zen"
+	MODE_HARD    = "hard"
+	MODE_TIMED   = "timed"
+)
+
+var (
+	HARD_ATTEMPTS = 15
+	TIME_LIMIT    = 5 * time.Minute
+	MODE_NAMES    = []string{MODE_CLASSIC, MODE_ZEN, MODE_HARD, MODE_TIMED}
+)
+
+// GetMode falls back to classic for unknown names. Modes are built on every
+// call so changes to the attempt and time limits apply.
+func GetMode(name string) m.Mode {
+	switch name {
+	case MODE_ZEN:
+		return m.Mode{Name: MODE_ZEN}
+	case MODE_HARD:
+		return m.Mode{Name: MODE_HARD, MaxAttempts: HARD_ATTEMPTS, NoTargetInput: true, Scored: true, ScoreFactor: 1.5}
+	case MODE_TIMED:
+		return m.Mode{Name: MODE_TIMED, MaxAttempts: MAX_ATTEMPTS, TimeLimit: TIME_LIMIT, Scored: true, TimeBonus: 50}
+	}
+	return m.Mode{Name: MODE_CLASSIC, MaxAttempts: MAX_ATTEMPTS, Scored: true}
+}
+
+// usesTargetWords tells whether input contains a challenge word that isn't
+// given for free.
+func (g *Game) usesTargetWords(challenge m.Challenge, input string) (bool, error) {
+	found, err := matcher.Match(g.Stemmer, input, challenge, nil)
+	if err != nil {
+		return false, err
+	}
+	given := challenge.Given()
+	for i := range found {
+		if found[i] && !given[i] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
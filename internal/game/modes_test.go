@@ -0,0 +1,37 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestGetModeFollowsLimits(t *testing.T) {
+	defer func(attempts int) { HARD_ATTEMPTS = attempts }(HARD_ATTEMPTS)
+	HARD_ATTEMPTS = 7
+	if mode := GetMode(MODE_HARD); mode.MaxAttempts != 7 {
+		t.Errorf("hard mode allows %d attempts, want 7", mode.MaxAttempts)
+	}
+	if mode := GetMode("nope"); mode.Name != MODE_CLASSIC || mode.MaxAttempts != MAX_ATTEMPTS {
+		t.Errorf("unknown mode %+v", mode)
+	}
+}
+
+func TestModeSwitchKeepsClock(t *testing.T) {
+	g := GetGame()
+	g.SetChallenges(m.GetChallenges())
+	s := g.NewState("switch", "", "en", "", 0)
+	started := time.Now().Add(-time.Minute)
+	s.Started = started
+	g.SessionManager.SetState(s.ID, s)
+
+	r := httptest.NewRequest("GET", "/game?mode="+MODE_TIMED, nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: s.ID})
+	g.Getgamestate(httptest.NewRecorder(), r)
+	if s.Mode != MODE_TIMED || !s.Started.Equal(started) {
+		t.Errorf("mode %q started %v, want %v", s.Mode, s.Started, started)
+	}
+}
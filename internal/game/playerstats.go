@@ -0,0 +1,33 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/stats"
+)
+
+// GET /stats, the `tz` param picks where the player's days start
+func (g *Game) Getstats(w http.ResponseWriter, r *http.Request) {
+	if g.Stats == nil {
+		http.Error(w, "Stats Not Available", http.StatusNotFound)
+		return
+	}
+	player := g.SessionManager.GetPlayerID(w, r)
+	tz := r.URL.Query().Get("tz")
+	if sessionID, err := g.SessionManager.GetSessionID(r); err == nil && tz == "" {
+		if state, exists := g.SessionManager.GetState(sessionID); exists {
+			tz = state.Timezone
+		}
+	}
+	res, err := g.Stats.Get(player, stats.Location(tz), time.Now())
+	if err != nil {
+		log.Printf("Loading stats failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/card"
+	db "github.com/kirtansoni/words-weave/internal/database"
+	f "github.com/kirtansoni/words-weave/internal/frontend"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/share"
+	"github.com/kirtansoni/words-weave/internal/stats"
+)
+
+// result sums up the current challenge of a state.
+func (g *Game) result(state *m.State) m.Result {
+	challenge, _ := g.challenge(state)
+	mode := GetMode(state.Mode)
+	return m.Result{
+		Challenge:   state.Challenge,
+		Mode:        mode.Name,
+		Attempts:    state.Attempts,
+		MaxAttempts: mode.MaxAttempts,
+		Passages:    len(state.Content),
+		Unlocked:    state.Unlocked(challenge.Given()),
+		Hints:       len(state.Hints),
+		Solved:      state.Solved(),
+		Score:       mode.Score(challenge, state),
+		Practice:    practiceOf(state),
+	}
+}
+
+// GET /game/share
+func (g *Game) Getshare(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := g.SessionManager.GetSessionID(r)
+	if err != nil || sessionID == "" {
+		http.Error(w, "No Session Detected", http.StatusRequestTimeout)
+		return
+	}
+	key, err := g.stateKey(sessionID, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	state, exists := g.SessionManager.GetState(key)
+	if !exists {
+		http.Error(w, "Session Expired", http.StatusRequestTimeout)
+		return
+	}
+
+	//the challenge being played is left out until it's over
+	results := state.Results
+	if g.isComplete(state) {
+		results = append(results[:len(results):len(results)], g.result(state))
+	}
+	if len(results) == 0 {
+		http.Error(w, "Nothing to share yet", http.StatusNotFound)
+		return
+	}
+
+	// the day the state was played, which lags behind the clock when a
+	// rollover failed or was forced
+	day := share.Day(time.Now())
+	if played, err := time.Parse(db.DAY_FORMAT, state.Day); err == nil {
+		day = share.Day(played)
+	}
+	summary := share.New(share.ID(key, day), day, state.Language, results)
+	summary.Practice = state.Practice
+	if g.Stats != nil && state.Player != "" && !state.Practice {
+		if playerStats, err := g.Stats.Get(state.Player, stats.Location(state.Timezone), time.Now()); err == nil {
+			summary.Streak = playerStats.CurrentStreak
+		}
+	}
+	if g.Shares != nil {
+		summary.URL = baseURL(r) + "/r/" + summary.ID
+		if err := g.Shares.Save(summary); err != nil {
+			log.Printf("Saving shared result failed: %v", err)
+			summary.URL = ""
+		}
+	}
+	summary.Text = share.Text(summary, summary.URL)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GET /r/{id}, public. Browsers get the app with meta tags for link
+// previews, anything else the share text.
+func (g *Game) Getsharedresult(w http.ResponseWriter, r *http.Request) {
+	summary, ok := g.sharedSummary(w, r)
+	if !ok {
+		return
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write([]byte(share.Text(summary, "")))
+		return
+	}
+
+	base := baseURL(r)
+	title := fmt.Sprintf("%s #%d", share.TITLE, summary.Day)
+	image := fmt.Sprintf("%s/r/%s/card.png?v=%s", base, summary.ID, share.Version(summary))
+	var head strings.Builder
+	for _, tag := range [][2]string{
+		{"og:type", "website"},
+		{"og:title", title},
+		{"og:description", share.Text(summary, "")},
+		{"og:url", base + "/r/" + summary.ID},
+		{"og:image", image},
+		{"og:image:width", fmt.Sprint(card.WIDTH)},
+		{"og:image:height", fmt.Sprint(card.HEIGHT)},
+		{"twitter:card", "summary_large_image"},
+		{"twitter:title", title},
+		{"twitter:image", image},
+	} {
+		attr := "property"
+		if strings.HasPrefix(tag[0], "twitter:") {
+			attr = "name"
+		}
+		fmt.Fprintf(&head, "  <meta %s=\"%s\" content=\"%s\" />\n", attr, tag[0], html.EscapeString(tag[1]))
+	}
+	index, err := f.GetReactSPA().Index(head.String())
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(index)
+}
+
+// GET /r/{id}/card.png, public. The URL carries the summary's version so
+// the card can be cached for long.
+func (g *Game) Getsharecard(w http.ResponseWriter, r *http.Request) {
+	summary, ok := g.sharedSummary(w, r)
+	if !ok {
+		return
+	}
+	version := share.Version(summary)
+	etag := `"` + version + `"`
+	w.Header().Set("ETag", etag)
+	if r.URL.Query().Get("v") == version {
+		w.Header().Set("Cache-Control", "public, max-age=604800, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := card.Render(&b, summary); err != nil {
+		log.Printf("Rendering share card failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(b.Bytes())
+}
+
+// sharedSummary loads the summary of the permalink, it writes the error
+// response itself when there is none.
+func (g *Game) sharedSummary(w http.ResponseWriter, r *http.Request) (share.Summary, bool) {
+	if g.Shares == nil {
+		http.NotFound(w, r)
+		return share.Summary{}, false
+	}
+	summary, err := g.Shares.Get(r.PathValue("id"))
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return summary, false
+	}
+	if err != nil {
+		log.Printf("Loading shared result failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return summary, false
+	}
+	return summary, true
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
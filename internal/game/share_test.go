@@ -0,0 +1,36 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/share"
+)
+
+func TestShareUsesDayPlayed(t *testing.T) {
+	g := GetGame()
+	g.SetChallenges(m.GetChallenges())
+	// the rollover to today never happened
+	g.Day = "2025-06-01"
+	s := g.NewState("share", "", "en", "", 0)
+	for i := range s.Progress {
+		s.Progress[i] = true
+	}
+	g.SessionManager.SetState(s.ID, s)
+
+	r := httptest.NewRequest("GET", "/game/share", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: s.ID})
+	w := httptest.NewRecorder()
+	g.Getshare(w, r)
+	var summary share.Summary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("%d: %s", w.Code, w.Body)
+	}
+	if want := share.Day(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)); summary.Day != want {
+		t.Errorf("day %d, want %d", summary.Day, want)
+	}
+}
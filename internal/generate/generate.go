@@ -0,0 +1,128 @@
+package generate
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/quality"
+)
+
+var (
+	// passages tried for a quote before it is left for later
+	MAX_TRIES = 3
+	// a topic isn't used again for this many days
+	TOPIC_DAYS = 30
+	//go:embed topics.txt
+	topicList string
+	TOPICS    = parseTopics(topicList)
+
+	ErrNoTopics = errors.New("every topic was used recently")
+)
+
+func parseTopics(list string) []string {
+	var topics []string
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			topics = append(topics, line)
+		}
+	}
+	return topics
+}
+
+// Generator writes starting passages for new quotes. A passage the
+// validator fails is tried again on another topic, accepted ones make the
+// quote a draft an admin approves.
+type Generator struct {
+	DB        *sql.DB
+	LLM       l.Provider
+	Validator quality.Validator
+	// TOPICS when empty
+	Topics []string
+	Seed   uint64
+	now    func() time.Time
+}
+
+// Result is what came of one quote.
+type Result struct {
+	Quote    int    `json:"quote"`
+	Topic    string `json:"topic"`
+	Tries    int    `json:"tries"`
+	Accepted bool   `json:"accepted"`
+	// of the last passage tried
+	Report quality.Report `json:"report"`
+}
+
+// Run writes passages for up to limit new quotes, all of them when limit
+// is 0.
+func (g *Generator) Run(ctx context.Context, limit int) ([]Result, error) {
+	now := time.Now
+	if g.now != nil {
+		now = g.now
+	}
+	topics := g.Topics
+	if len(topics) == 0 {
+		topics = TOPICS
+	}
+	quotes, err := db.GetQuotes(g.DB, db.QUOTE_NEW, limit)
+	if err != nil {
+		return nil, err
+	}
+	used, err := db.RecentTopics(g.DB, db.Today(now().AddDate(0, 0, -TOPIC_DAYS)))
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewPCG(g.Seed, uint64(now().Unix())))
+
+	var results []Result
+	for _, quote := range quotes {
+		result := Result{Quote: quote.ID}
+		var tried []string
+		for result.Tries < MAX_TRIES && !result.Accepted {
+			var fresh []string
+			for _, topic := range topics {
+				if !slices.Contains(used, topic) && !slices.Contains(tried, topic) {
+					fresh = append(fresh, topic)
+				}
+			}
+			if len(fresh) == 0 {
+				return results, ErrNoTopics
+			}
+			result.Topic = fresh[rng.IntN(len(fresh))]
+			tried = append(tried, result.Topic)
+			result.Tries++
+
+			content, err := g.LLM.Write(ctx, result.Topic, quote.GetLanguage())
+			if err != nil {
+				if ctx.Err() != nil {
+					return results, ctx.Err()
+				}
+				log.Printf("Writing a passage for quote %d failed: %v", quote.ID, err)
+				continue
+			}
+			challenge := quote.Challenge
+			challenge.Content = content
+			if result.Report, err = g.Validator.Validate(challenge); err != nil {
+				return results, err
+			}
+			if result.Report.Status == quality.FAIL {
+				continue
+			}
+			if err := db.SaveDraft(g.DB, quote.ID, content, result.Topic); err != nil {
+				return results, err
+			}
+			result.Accepted = true
+			used = append(used, result.Topic)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
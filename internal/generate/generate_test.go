@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/quality"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+// hands out passages in order, the last one from then on
+type scripted struct {
+	l.Fake
+	passages []string
+	topics   []string
+}
+
+func (s *scripted) Write(ctx context.Context, topic string, language string) (string, error) {
+	s.topics = append(s.topics, topic)
+	return s.passages[min(len(s.topics), len(s.passages))-1], nil
+}
+
+func TestRun(t *testing.T) {
+	database, err := db.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	quote := func(text string) m.Challenge {
+		c := m.Challenge{Quote: text, Author: "Anon"}
+		c.Words = tokenizer.Words(c.Tokens())
+		return c
+	}
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	// played last week, its topic is taken
+	if _, err := db.AddQuote(database, db.Quote{Challenge: quote("Old news."), Status: db.QUOTE_LIVE, Topic: "comets"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`UPDATE quotes SET play_date = '2026-05-03'`); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"Fortune favours the brave.", "Patience is bitter, but its fruit is sweet."} {
+		if _, err := db.AddQuote(database, db.Quote{Challenge: quote(text)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	llm := &scripted{passages: []string{
+		// hands the answer over, fails
+		"Fortune favours the brave.",
+		"Sailors once crossed the northern sea in small wooden boats, reading stars, currents and " +
+			"the flight of seabirds to find distant islands where forests met cold rocky shores.",
+	}}
+	g := &Generator{
+		DB:        database,
+		LLM:       llm,
+		Validator: quality.Validator{Stemmer: matcher.LocalStemmer{}},
+		Topics:    []string{"comets", "glaciers", "orchids", "windmills"},
+		now:       func() time.Time { return now },
+	}
+	results, err := g.Run(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Tries != 2 || !results[0].Accepted || results[1].Tries != 1 || !results[1].Accepted {
+		t.Fatalf("got %+v", results)
+	}
+	if slices.Contains(llm.topics, "comets") {
+		t.Errorf("reused a recent topic: %v", llm.topics)
+	}
+	if results[0].Topic == results[1].Topic {
+		t.Errorf("two drafts on %s", results[0].Topic)
+	}
+
+	drafts, err := db.GetQuotes(database, db.QUOTE_DRAFT, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 2 || !strings.HasPrefix(drafts[0].Content, "Sailors") || drafts[1].Topic != results[1].Topic {
+		t.Errorf("drafts %+v", drafts)
+	}
+	if fresh, _ := db.GetQuotes(database, db.QUOTE_NEW, 0); len(fresh) != 0 {
+		t.Errorf("left new: %+v", fresh)
+	}
+
+	// approved drafts take the next slots of the day
+	for i, draft := range drafts {
+		ordinal, err := db.ApproveDraft(database, draft.ID, "2026-05-11", "2026-05-10", 3)
+		if err != nil || ordinal != i {
+			t.Fatalf("approve %d: %d %v", draft.ID, ordinal, err)
+		}
+	}
+	if day, _ := db.GetChallenges(database, "2026-05-11"); len(day) != 2 {
+		t.Errorf("scheduled %+v", day)
+	}
+	if _, err := db.ApproveDraft(database, drafts[0].ID, "2026-05-12", "2026-05-10", 3); err == nil {
+		t.Error("approved a live quote")
+	}
+	if _, err := db.ApproveDraft(database, drafts[1].ID, "2026-05-09", "2026-05-10", 3); !errors.Is(err, db.ErrPastDay) {
+		t.Errorf("approved for a day that is over: %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package importer
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// quotes shorter or longer than this, in words, make poor challenges
+	MIN_WORDS = 4
+	MAX_WORDS = 30
+	// long quotes this many words apart, per ten words, are the same quote.
+	// One word changes the meaning of a shorter quote, they are only the
+	// same when they differ in stopwords and punctuation.
+	NEAR_DISTANCE = 1
+	LONG_QUOTE    = 20
+)
+
+const (
+	FORMAT_CSV       = "csv"
+	FORMAT_JSON      = "json"
+	FORMAT_ZENQUOTES = "zenquotes"
+)
+
+const (
+	REJECT_EMPTY     = "empty"
+	REJECT_SHORT     = "too short"
+	REJECT_LONG      = "too long"
+	REJECT_DUPLICATE = "duplicate"
+)
+
+// Record is one quote of a collection, before it is checked.
+type Record struct {
+	Quote    string `json:"quote"`
+	Author   string `json:"author"`
+	Source   string `json:"source"`
+	License  string `json:"license"`
+	Language string `json:"language"`
+}
+
+// Rejection is a record that wasn't imported and why.
+type Rejection struct {
+	// position in the collection, from 1
+	Record int    `json:"record"`
+	Quote  string `json:"quote"`
+	Reason string `json:"reason"`
+}
+
+type Result struct {
+	Imported int         `json:"imported"`
+	Rejected []Rejection `json:"rejected"`
+}
+
+// Importer adds quote collections to the quotes table as new quotes, the
+// generator writes their starting content. Source, License and Language
+// fill in what records leave empty.
+type Importer struct {
+	MinWords int
+	MaxWords int
+	Source   string
+	License  string
+	Language string
+}
+
+func New() *Importer {
+	return &Importer{MinWords: MIN_WORDS, MaxWords: MAX_WORDS}
+}
+
+// Format guesses the format of a file from its name and, for JSON, from
+// the keys of its records.
+func Format(path string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FORMAT_CSV, nil
+	case ".json":
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			return "", err
+		}
+		if len(records) > 0 {
+			if _, ok := records[0]["q"]; ok {
+				return FORMAT_ZENQUOTES, nil
+			}
+		}
+		return FORMAT_JSON, nil
+	}
+	return "", fmt.Errorf("unknown format of %s", path)
+}
+
+// Parse reads the records of a collection.
+func Parse(format string, r io.Reader) ([]Record, error) {
+	switch format {
+	case FORMAT_CSV:
+		return parseCSV(r)
+	case FORMAT_JSON:
+		var records []Record
+		err := json.NewDecoder(r).Decode(&records)
+		return records, err
+	case FORMAT_ZENQUOTES:
+		// the dumps of the ZenQuotes API, q is the quote and a the author
+		var raw []struct {
+			Quote  string `json:"q"`
+			Author string `json:"a"`
+		}
+		if err := json.NewDecoder(r).Decode(&raw); err != nil {
+			return nil, err
+		}
+		records := make([]Record, len(raw))
+		for i, q := range raw {
+			records[i] = Record{Quote: q.Quote, Author: q.Author, Source: "ZenQuotes.io"}
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// parseCSV reads a header row naming the columns, quote is required and
+// author, source, license and language are optional.
+func parseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["quote"]; !ok {
+		return nil, errors.New("csv has no quote column")
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, Record{
+			Quote:    field(row, "quote"),
+			Author:   field(row, "author"),
+			Source:   field(row, "source"),
+			License:  field(row, "license"),
+			Language: field(row, "language"),
+		})
+	}
+}
+
+// ImportFile reads a collection in format, guessed from the file when
+// empty, and imports it.
+func (im *Importer) ImportFile(database *sql.DB, path, format string) (Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	if format == "" {
+		if format, err = Format(path, data); err != nil {
+			return Result{}, err
+		}
+	}
+	records, err := Parse(format, bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return im.Import(database, records)
+}
+
+// Import checks and normalizes records and adds the good ones. Quotes
+// close to one already stored, or earlier in records, are duplicates.
+func (im *Importer) Import(database *sql.DB, records []Record) (Result, error) {
+	result := Result{Rejected: []Rejection{}}
+	texts, err := db.QuoteTexts(database)
+	if err != nil {
+		return result, err
+	}
+	seen := newIndex()
+	for language, quotes := range texts {
+		language = m.Challenge{Language: language}.GetLanguage()
+		for _, text := range quotes {
+			seen.add(language, key(text))
+		}
+	}
+
+	var quotes []db.Quote
+	for i, record := range records {
+		q := im.quote(record)
+		reject := func(reason string) {
+			result.Rejected = append(result.Rejected, Rejection{Record: i + 1, Quote: q.Quote, Reason: reason})
+		}
+		n := len(q.Words)
+		switch {
+		case n == 0:
+			reject(REJECT_EMPTY)
+			continue
+		case n < im.MinWords:
+			reject(REJECT_SHORT)
+			continue
+		case im.MaxWords > 0 && n > im.MaxWords:
+			reject(REJECT_LONG)
+			continue
+		}
+		k := key(q.Quote)
+		if seen.contains(q.GetLanguage(), k) {
+			reject(REJECT_DUPLICATE)
+			continue
+		}
+		seen.add(q.GetLanguage(), k)
+		quotes = append(quotes, q)
+	}
+	if err := db.AddQuotes(database, quotes); err != nil {
+		return result, err
+	}
+	result.Imported = len(quotes)
+	return result, nil
+}
+
+func (im *Importer) quote(record Record) db.Quote {
+	q := db.Quote{
+		Challenge: m.Challenge{
+			Quote:    Normalize(record.Quote),
+			Author:   normalizeAuthor(record.Author),
+			Language: strings.ToLower(strings.TrimSpace(record.Language)),
+		},
+		Status:  db.QUOTE_NEW,
+		Source:  Normalize(record.Source),
+		License: Normalize(record.License),
+	}
+	if q.Language == "" {
+		q.Language = im.Language
+	}
+	if q.Source == "" {
+		q.Source = im.Source
+	}
+	if q.License == "" {
+		q.License = im.License
+	}
+	if q.Author == "" {
+		q.Author = "Unknown"
+	}
+	q.Words = tokenizer.Words(q.Tokens())
+	return q
+}
+
+var typography = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u2032", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u00ab", `"`, "\u00bb", `"`,
+	"\u2013", "-", "\u2014", " - ", "\u2026", "...",
+	// no-break and thin spaces, zero-width space and byte order mark
+	"\u00a0", " ", "\u2009", " ", "\u200b", "", "\ufeff", "",
+)
+
+// Normalize straightens quotes and dashes, collapses whitespace and drops
+// quotation marks around the whole text.
+func Normalize(text string) string {
+	text = strings.Join(strings.Fields(typography.Replace(text)), " ")
+	for len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' && !strings.Contains(text[1:len(text)-1], `"`) {
+		text = strings.TrimSpace(text[1 : len(text)-1])
+	}
+	return text
+}
+
+// authors often come as "- Name" or "~ Name"
+func normalizeAuthor(author string) string {
+	return strings.TrimLeft(Normalize(author), "-~ ")
+}
+
+// key is the words of a quote, without case or punctuation.
+func key(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(Normalize(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// index finds the quotes a new one duplicates without comparing it to
+// every quote. Quotes are found by their words without stopwords, long ones
+// also by edit distance to quotes of about their length.
+type index struct {
+	content map[string]bool
+	// language to length in words to the words of long quotes
+	long map[string]map[int][][]string
+}
+
+func newIndex() *index {
+	return &index{content: make(map[string]bool), long: make(map[string]map[int][][]string)}
+}
+
+// contentKey drops stopwords, unless the quote is nothing else.
+func contentKey(language string, words []string) string {
+	set := stopwords.Get(language)
+	var content []string
+	for _, word := range words {
+		if !set.Contains(word) {
+			content = append(content, word)
+		}
+	}
+	if len(content) == 0 {
+		content = words
+	}
+	return language + ":" + strings.Join(content, " ")
+}
+
+// allowed is how many words a quote this long may be apart from its duplicates
+func allowed(n int) int {
+	return NEAR_DISTANCE * (n / 10)
+}
+
+func (x *index) add(language string, words []string) {
+	x.content[contentKey(language, words)] = true
+	if len(words) < LONG_QUOTE {
+		return
+	}
+	if x.long[language] == nil {
+		x.long[language] = make(map[int][][]string)
+	}
+	x.long[language][len(words)] = append(x.long[language][len(words)], words)
+}
+
+func (x *index) contains(language string, words []string) bool {
+	if x.content[contentKey(language, words)] {
+		return true
+	}
+	if len(words) < LONG_QUOTE {
+		return false
+	}
+	// the length differs at least as much as the words
+	n := allowed(len(words))
+	for length := len(words) - n; length <= len(words)+n; length++ {
+		for _, other := range x.long[language][length] {
+			if distance(words, other) <= min(n, allowed(length)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// distance is the edit distance between two quotes, in words.
+func distance(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	db "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  “Don’t   count the days,\n make the days count.”  ": `Don't count the days, make the days count.`,
+		"Wait—what? Yes…":      "Wait - what? Yes...",
+		`"He said "no" twice"`: `"He said "no" twice"`,
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	database, err := db.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	stored := db.Quote{Challenge: m.Challenge{Quote: "Stay hungry, stay foolish, keep going."}}
+	if _, err := db.AddQuote(database, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	csvData := "Quote,Author,License\n" +
+		"\"The journey of a thousand miles begins with one step.\",— Lao Tzu,CC0\n" +
+		"\"A journey of a thousand miles begins with one step!\",Lao Tzu,CC0\n" +
+		"Too short,Nobody,\n" +
+		"\"Stay hungry, stay foolish, keep on going.\",Steve Jobs,\n"
+	records, err := Parse(FORMAT_CSV, strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zen, err := Parse(FORMAT_ZENQUOTES, strings.NewReader(`[{"q": "Well done is better than well said. ", "a": "Benjamin Franklin", "h": "<blockquote>"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	im := New()
+	im.Source = "test collection"
+	result, err := im.Import(database, append(records, zen...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 2 || len(result.Rejected) != 3 {
+		t.Fatalf("got %+v", result)
+	}
+	reasons := []string{REJECT_DUPLICATE, REJECT_SHORT, REJECT_DUPLICATE}
+	for i, rejection := range result.Rejected {
+		if rejection.Reason != reasons[i] || rejection.Record != i+2 {
+			t.Errorf("rejection %d: %+v", i, rejection)
+		}
+	}
+
+	quotes, err := db.GetQuotes(database, db.QUOTE_NEW, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("got %+v", quotes)
+	}
+	first, zenQuote := quotes[1], quotes[2]
+	if first.Author != "Lao Tzu" || first.License != "CC0" || first.Source != "test collection" || len(first.Words) != 10 {
+		t.Errorf("got %+v", first)
+	}
+	if zenQuote.Quote != "Well done is better than well said." || zenQuote.Source != "ZenQuotes.io" {
+		t.Errorf("got %+v", zenQuote)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	long := "the best way to find out if you can trust somebody is to trust them and see what happens next in the end"
+	tests := []struct {
+		a, b      string
+		duplicate bool
+	}{
+		{"Love conquers all fears.", "Love conquers all things.", false},
+		{"Love conquers all fears.", "Love conquers all of the fears!", true},
+		{"Love conquers all fears.", "Fears conquer all love.", false},
+		{long, strings.Replace(long, "best", "only", 1), true},
+		{long, strings.Replace(strings.Replace(strings.Replace(long, "best", "only", 1), "trust", "know", 1), "see", "watch", 1), false},
+		{long, "the best way to find out if you can trust somebody", false},
+	}
+	for _, tt := range tests {
+		x := newIndex()
+		x.add("en", key(tt.a))
+		if got := x.contains("en", key(tt.b)); got != tt.duplicate {
+			t.Errorf("%q and %q: duplicate %v, want %v", tt.a, tt.b, got, tt.duplicate)
+		}
+		if x.contains("es", key(tt.a)) {
+			t.Errorf("%q: quotes of other languages are no duplicates", tt.a)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+const (
+	DAILY   = "daily"
+	WEEKLY  = "weekly"
+	ALLTIME = "alltime"
+
+	// total score, highest first
+	BY_SCORE = "score"
+	// most challenges solved, then fewest attempts spent on them
+	BY_ATTEMPTS = "attempts"
+)
+
+var (
+	// a challenge solved faster than this wasn't played by a person
+	MIN_DURATION = 10 * time.Second
+	// nor were passages asked for faster than this on average, a passage
+	// alone takes longer to read
+	MIN_ATTEMPT_INTERVAL = 3 * time.Second
+	NAME_PATTERN         = regexp.MustCompile(`^[\pL\pN _.-]{3,20}$`)
+	ErrInvalidName       = errors.New("names are 3 to 20 letters, digits, spaces, dots, dashes or underscores")
+	ErrNameTaken         = errors.New("name is taken")
+)
+
+// Board ranks opted in players by day, week and overall. Every finished
+// challenge is added to its periods as it happens, so ranking never
+// needs the sessions.
+type Board struct {
+	db *sql.DB
+}
+
+// Entry is a player's line on a board.
+type Entry struct {
+	Rank     int    `json:"rank"`
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+	Solved   int    `json:"solved"`
+	Attempts int    `json:"attempts"`
+}
+
+// Page is part of a board and where the requesting player stands on it.
+type Page struct {
+	Board   string  `json:"board"`
+	By      string  `json:"by"`
+	Period  string  `json:"period"`
+	Total   int     `json:"total"`
+	Entries []Entry `json:"entries"`
+	// nil when the player isn't on the board
+	Me *Entry `json:"me"`
+}
+
+func New(db *sql.DB) *Board {
+	return &Board{db: db}
+}
+
+// Period is the key of the board's period containing t, days and weeks
+// are UTC like the daily challenges.
+func Period(board string, t time.Time) string {
+	t = t.UTC()
+	switch board {
+	case DAILY:
+		return t.Format("2006-01-02")
+	case WEEKLY:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return ""
+}
+
+// OptIn puts the player on the boards under name, or renames them.
+func (b *Board) OptIn(player, name string) error {
+	name = strings.TrimSpace(name)
+	if !NAME_PATTERN.MatchString(name) {
+		return ErrInvalidName
+	}
+	var owner string
+	err := b.db.QueryRow(`SELECT player FROM leaderboard_players WHERE name = ?`, name).Scan(&owner)
+	if err == nil && owner != player {
+		return ErrNameTaken
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	_, err = b.db.Exec(`INSERT INTO leaderboard_players (player, name) VALUES (?, ?)
+		ON CONFLICT (player) DO UPDATE SET name = excluded.name`, player, name)
+	return err
+}
+
+// OptOut takes the player off the boards, their results are kept in case
+// they come back.
+func (b *Board) OptOut(player string) error {
+	_, err := b.db.Exec(`DELETE FROM leaderboard_players WHERE player = ?`, player)
+	return err
+}
+
+// Suspicious tells whether a result looks scripted. Only attempts that
+// generated a passage count, hints take attempts without any waiting.
+func Suspicious(result m.Result, started, finished time.Time) bool {
+	took := finished.Sub(started)
+	if result.Solved && took < MIN_DURATION {
+		return true
+	}
+	return result.Passages > 1 && took < time.Duration(result.Passages)*MIN_ATTEMPT_INTERVAL
+}
+
+// Record adds a finished challenge of the set of day to the boards of its
+// periods. A challenge is only counted once, recording it again does
+// nothing. Unscored results, practice and scripted looking results don't
+// count.
+func (b *Board) Record(player, language, day string, result m.Result, mode m.Mode, started, finished time.Time) error {
+	if !mode.Scored || result.Practice != "" || Suspicious(result, started, finished) {
+		return nil
+	}
+
+	solved := 0
+	if result.Solved {
+		solved = 1
+	}
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`INSERT OR IGNORE INTO leaderboard_results (player, day, language, challenge) VALUES (?, ?, ?, ?)`,
+		player, day, language, result.Challenge)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	for _, board := range []string{DAILY, WEEKLY, ALLTIME} {
+		_, err := tx.Exec(`INSERT INTO leaderboard_entries (board, period, player, score, solved, attempts, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (board, period, player) DO UPDATE SET score = score + excluded.score,
+				solved = solved + excluded.solved, attempts = attempts + excluded.attempts,
+				updated_at = excluded.updated_at`,
+			board, Period(board, finished), player, result.Score, solved, result.Attempts, finished)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// order is the ORDER BY of a ranking and better is the condition for a
+// row o ranking strictly above row e, ties share a rank.
+func order(by string) (order, better string) {
+	if by == BY_ATTEMPTS {
+		return "e.solved DESC, e.attempts ASC",
+			"(o.solved > e.solved OR (o.solved = e.solved AND o.attempts < e.attempts))"
+	}
+	return "e.score DESC", "o.score > e.score"
+}
+
+// Get returns limit entries of a board from offset, ranked by, along with
+// the player's own entry.
+func (b *Board) Get(board, by, period, player string, limit, offset int) (Page, error) {
+	page := Page{Board: board, By: by, Period: period, Entries: []Entry{}}
+	order, better := order(by)
+	// only opted in players are ranked
+	from := `FROM leaderboard_entries e JOIN leaderboard_players p ON p.player = e.player
+		WHERE e.board = ? AND e.period = ?`
+	rank := `1 + (SELECT COUNT(*) FROM leaderboard_entries o JOIN leaderboard_players op ON op.player = o.player
+		WHERE o.board = e.board AND o.period = e.period AND ` + better + `)`
+	if by == BY_ATTEMPTS {
+		from += " AND e.solved > 0"
+	}
+
+	if err := b.db.QueryRow(`SELECT COUNT(*) `+from, board, period).Scan(&page.Total); err != nil {
+		return page, err
+	}
+	rows, err := b.db.Query(`SELECT `+rank+`, p.name, e.score, e.solved, e.attempts `+from+`
+		ORDER BY `+order+`, p.name LIMIT ? OFFSET ?`, board, period, limit, offset)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.Rank, &entry.Name, &entry.Score, &entry.Solved, &entry.Attempts); err != nil {
+			return page, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	var me Entry
+	err = b.db.QueryRow(`SELECT `+rank+`, p.name, e.score, e.solved, e.attempts `+from+` AND e.player = ?`,
+		board, period, player).Scan(&me.Rank, &me.Name, &me.Score, &me.Solved, &me.Attempts)
+	if err == nil {
+		page.Me = &me
+	} else if err != sql.ErrNoRows {
+		return page, err
+	}
+	return page, nil
+}
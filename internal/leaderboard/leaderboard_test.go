@@ -0,0 +1,162 @@
+package leaderboard
+
+import (
+	"testing"
+	"time"
+
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func testBoard(t *testing.T) *Board {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	b := New(db)
+	return b
+}
+
+func TestRanking(t *testing.T) {
+	b := testBoard(t)
+	classic := m.Mode{Scored: true}
+	started := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	finished := started.Add(5 * time.Minute)
+	day := Period(DAILY, finished)
+
+	players := []struct {
+		player, name string
+		score        int
+		attempts     int
+	}{
+		{"p1", "ada", 100, 9},
+		{"p2", "bob", 80, 4},
+		{"p3", "cy", 0, 0},
+		{"p4", "dee", 80, 6},
+		{"p5", "eve", 100, 12},
+	}
+	for _, p := range players {
+		// cy plays without opting in
+		if p.player != "p3" {
+			if err := b.OptIn(p.player, p.name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		result := m.Result{Attempts: p.attempts, Score: p.score, Solved: true}
+		if err := b.Record(p.player, "en", day, result, classic, started, finished); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.OptIn("p3", "ADA"); err != ErrNameTaken {
+		t.Errorf("names are case insensitive, got %v", err)
+	}
+	// a bot finishing in two seconds stays off
+	b.OptIn("bot", "robot")
+	b.Record("bot", "en", day, m.Result{Attempts: 3, Score: 100, Solved: true}, classic, started, started.Add(2*time.Second))
+
+	page, err := b.Get(DAILY, BY_SCORE, day, "p4", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 4 || len(page.Entries) != 2 {
+		t.Fatalf("page %+v", page)
+	}
+	// ties share a rank
+	if page.Entries[0].Name != "bob" || page.Entries[0].Rank != 3 || page.Entries[1].Rank != 3 {
+		t.Errorf("entries %+v", page.Entries)
+	}
+	if page.Me == nil || page.Me.Name != "dee" || page.Me.Rank != 3 {
+		t.Errorf("me %+v", page.Me)
+	}
+
+	page, err = b.Get(DAILY, BY_ATTEMPTS, day, "p5", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Entries[0].Name != "bob" || page.Me == nil || page.Me.Rank != 4 {
+		t.Errorf("attempts board %+v, me %+v", page.Entries, page.Me)
+	}
+
+	if page, _ := b.Get(ALLTIME, BY_SCORE, "", "bot", 10, 0); page.Me != nil || page.Total != 4 {
+		t.Errorf("scripted result is ranked: %+v", page)
+	}
+}
+
+func TestSuspiciousResults(t *testing.T) {
+	b := testBoard(t)
+	classic := m.Mode{Scored: true}
+	started := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	b.OptIn("p1", "ada")
+
+	tests := []struct {
+		name       string
+		result     m.Result
+		took       time.Duration
+		suspicious bool
+	}{
+		{"played", m.Result{Attempts: 4, Passages: 4, Solved: true}, time.Minute, false},
+		{"solved instantly", m.Result{Attempts: 1, Passages: 1, Solved: true}, 2 * time.Second, true},
+		{"passages too fast", m.Result{Attempts: 8, Passages: 8}, 20 * time.Second, true},
+		// two reveals take six attempts but no time
+		{"reveals", m.Result{Attempts: 8, Passages: 2, Hints: 2, Solved: true, Score: 40}, 18 * time.Second, false},
+	}
+	for _, tt := range tests {
+		if got := Suspicious(tt.result, started, started.Add(tt.took)); got != tt.suspicious {
+			t.Errorf("%s: suspicious %v, want %v", tt.name, got, tt.suspicious)
+		}
+	}
+
+	// a scripted looking result is dropped, the player stays on the boards
+	b.Record("p1", "en", "2026-03-04", tests[1].result, classic, started, started.Add(tests[1].took))
+	b.Record("p1", "en", "2026-03-04", tests[3].result, classic, started, started.Add(tests[3].took))
+	page, err := b.Get(ALLTIME, BY_SCORE, "", "p1", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Me == nil || page.Me.Score != 40 || page.Me.Solved != 1 {
+		t.Errorf("me %+v", page.Me)
+	}
+	if err := b.OptOut("p1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.OptIn("p1", "ada"); err != nil {
+		t.Fatal(err)
+	}
+
+	// and nobody is put on them without opting in
+	b.Record("p2", "en", "2026-03-04", tests[1].result, classic, started, started.Add(tests[1].took))
+	var players int
+	b.db.QueryRow(`SELECT COUNT(*) FROM leaderboard_players`).Scan(&players)
+	if players != 1 {
+		t.Errorf("%d players opted in, want 1", players)
+	}
+}
+
+func TestRecordOnce(t *testing.T) {
+	b := testBoard(t)
+	classic := m.Mode{Scored: true}
+	started := time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)
+	finished := started.Add(5 * time.Minute)
+	b.OptIn("p1", "ada")
+
+	result := m.Result{Challenge: 1, Attempts: 4, Passages: 4, Score: 60, Solved: true}
+	for range 2 {
+		if err := b.Record("p1", "en", "2026-03-04", result, classic, started, finished); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the same challenge in another language is another result
+	if err := b.Record("p1", "es", "2026-03-04", result, classic, started, finished); err != nil {
+		t.Fatal(err)
+	}
+	for _, board := range []string{DAILY, WEEKLY, ALLTIME} {
+		page, err := b.Get(board, BY_SCORE, Period(board, finished), "p1", 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Me == nil || page.Me.Score != 120 || page.Me.Solved != 2 || page.Me.Attempts != 8 {
+			t.Errorf("%s: me %+v", board, page.Me)
+		}
+	}
+}
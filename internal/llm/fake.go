@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kirtansoni/words-weave/internal/nearmiss"
+)
+
+var (
+	// words of a fake passage
+	FAKE_LENGTH = 60
+	//go:embed fakewords
+	fakeWords embed.FS
+	fakeCache sync.Map
+)
+
+// Fake writes passages offline, for simulations and tests. A passage echoes
+// the input words, mixes in words the thesaurus relates to them and pads
+// with everyday words, the same input and seed always give the same passage.
+type Fake struct {
+	Seed uint64
+}
+
+func (f Fake) Model() string {
+	return "fake"
+}
+
+func (f Fake) Stream(ctx context.Context, input string, language string, output chan string) (string, error) {
+	defer close(output)
+	passage := f.Passage(input, language)
+	// a word per chunk, like a model streaming tokens
+	for _, word := range strings.SplitAfter(passage, " ") {
+		select {
+		case output <- word:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return passage, nil
+}
+
+// Write is a passage of the topic's words, like any other passage.
+func (f Fake) Write(ctx context.Context, topic string, language string) (string, error) {
+	return f.Passage(topic, language), ctx.Err()
+}
+
+func (f Fake) Passage(input string, language string) string {
+	h := fnv.New64a()
+	h.Write([]byte(language + "\x00" + strings.ToLower(input)))
+	rng := rand.New(rand.NewPCG(f.Seed, h.Sum64()))
+
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		words = append(words, word)
+		related := nearmiss.Related(language, word)
+		rng.Shuffle(len(related), func(i, j int) { related[i], related[j] = related[j], related[i] })
+		words = append(words, related[:min(3, len(related))]...)
+	}
+	vocabulary := getFakeWords(language)
+	for len(words) < FAKE_LENGTH && len(vocabulary) > 0 {
+		words = append(words, vocabulary[rng.IntN(len(vocabulary))])
+	}
+	rng.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	// sentences of six to twelve words
+	var b strings.Builder
+	for i := 0; i < len(words); {
+		n := min(len(words)-i, 6+rng.IntN(7))
+		sentence := strings.Join(words[i:i+n], " ")
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		first, size := utf8.DecodeRuneInString(sentence)
+		b.WriteString(string(unicode.ToUpper(first)) + sentence[size:] + ".")
+		i += n
+	}
+	return b.String()
+}
+
+func getFakeWords(language string) []string {
+	if words, ok := fakeCache.Load(language); ok {
+		return words.([]string)
+	}
+	var words []string
+	file, err := fakeWords.Open("fakewords/" + language + ".txt")
+	if err != nil {
+		file, err = fakeWords.Open("fakewords/en.txt")
+	}
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				words = append(words, line)
+			}
+		}
+	}
+	fakeCache.Store(language, words)
+	return words
+}
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFakeIsDeterministic(t *testing.T) {
+	fake := Fake{Seed: 3}
+	passage := fake.Passage("Rope knot", "en")
+	if again := fake.Passage("rope knot", "en"); again != passage {
+		t.Errorf("same input gave\n%q\n%q", passage, again)
+	}
+	if other := (Fake{Seed: 4}).Passage("rope knot", "en"); other == passage {
+		t.Error("another seed gave the same passage")
+	}
+	for _, word := range []string{"rope", "knot"} {
+		if !strings.Contains(strings.ToLower(passage), word) {
+			t.Errorf("%q is missing %q", passage, word)
+		}
+	}
+
+	chunks := make(chan string, 100)
+	streamed, err := fake.Stream(context.Background(), "rope knot", "en", chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var joined strings.Builder
+	for chunk := range chunks {
+		joined.WriteString(chunk)
+	}
+	if streamed != passage || joined.String() != passage {
+		t.Errorf("streamed %q, returned %q", joined.String(), streamed)
+	}
+}
@@ -2,7 +2,11 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+
 	"github.com/openai/openai-go"
 )
 
@@ -37,7 +41,54 @@ func LLMSummaries(s int, ctx context.Context) []string {
 	return contents
 }
 
-func StreamingLLM(input string, ctx context.Context, output chan string) string {
+// WritePassage asks for the starting passage of a challenge, an
+// encyclopedic paragraph about topic in the language of the challenge.
+func WritePassage(ctx context.Context, topic string, language string) (string, error) {
+	client := openai.NewClient()
+	completion, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(fmt.Sprintf(PASSAGE_PROMPT, topic, language)),
+		}),
+		Model: openai.F(openai.ChatModelGPT4o),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", ErrNoContent
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+var (
+	// asks for the starting passage of a challenge, with the topic and the
+	// ISO 639-1 code of the language
+	PASSAGE_PROMPT = "Generate a 100-word paragraph containing a fascinating, lesser-known fact about %s. Write as if extracted from an encyclopedia page - factual, informative, and engaging, with specific details, numbers, and concrete examples. Write it in the language with the ISO 639-1 code %q. Reply with the paragraph only."
+	// model writing the passages
+	MODEL = openai.ChatModelGPT3_5Turbo
+	// bump whenever SYSTEM_PROMPTS change, attempts are logged with it
+	PROMPT_VERSION = "1"
+	ErrNoContent   = errors.New("no choices returned")
+)
+
+// system prompts for passages, by the language of the challenge
+var SYSTEM_PROMPTS = map[string]string{
+	"en": "dont ask any questions, you are a autocomplete feature that will generate sentence of 100 words from the given word/words, dont ask for context, just reply with whatever comes to your mind",
+	"es": "no hagas preguntas, eres una función de autocompletado que genera un texto de 100 palabras en español a partir de la palabra o palabras dadas, no pidas contexto, responde solo con lo que se te ocurra",
+	"fr": "ne pose aucune question, tu es une fonction d'autocomplétion qui génère un texte de 100 mots en français à partir du ou des mots donnés, ne demande pas de contexte, réponds simplement avec ce qui te vient à l'esprit",
+	"de": "stelle keine Fragen, du bist eine Autovervollständigung, die aus dem gegebenen Wort oder den gegebenen Wörtern einen Text von 100 Wörtern auf Deutsch erzeugt, frage nicht nach Kontext, antworte einfach mit dem, was dir einfällt",
+}
+
+func SystemPrompt(language string) string {
+	if prompt, ok := SYSTEM_PROMPTS[language]; ok {
+		return prompt
+	}
+	return SYSTEM_PROMPTS["en"]
+}
+
+// StreamingLLM sends the passage for input to output as it is generated and
+// returns it whole once done.
+func StreamingLLM(input string, language string, ctx context.Context, output chan string) (string, error) {
 	// CRITICAL: Always close the channel, even on panic
 	defer func() {
 		if r := recover(); r != nil {
@@ -49,11 +100,11 @@ func StreamingLLM(input string, ctx context.Context, output chan string) string
 	client := openai.NewClient()
 	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
 		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage("dont ask any questions, you are a autocomplete feature that will generate sentence of 100 words from the given word/words, dont ask for context, just reply with whatever comes to your mind"),
+			openai.SystemMessage(SystemPrompt(language)),
 			openai.UserMessage(input),
 		}),
 		Seed:      openai.Int(0),
-		Model:     openai.F(openai.ChatModelGPT3_5Turbo),
+		Model:     openai.F(MODEL),
 		MaxTokens: openai.Int(150),
 	})
 
@@ -78,14 +129,14 @@ func StreamingLLM(input string, ctx context.Context, output chan string) string
 
 	if err := stream.Err(); err != nil {
 		log.Printf("Streaming error: %v", err)
-		return ""
+		return "", err
 	}
 
 	// CRITICAL FIX: Check if acc.Choices has any elements before accessing
 	if len(acc.Choices) == 0 {
 		log.Printf("No choices returned from OpenAI API for input: %s", input)
-		return ""
+		return "", ErrNoContent
 	}
 
-	return acc.Choices[0].Message.Content
+	return acc.Choices[0].Message.Content, nil
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+package llm
+
+import "context"
+
+// Provider writes passages. Stream sends the passage for input to output as
+// it is generated, closes output when done and returns the whole passage.
+type Provider interface {
+	Stream(ctx context.Context, input string, language string, output chan string) (string, error)
+	// Write is a starting passage about topic for a new challenge
+	Write(ctx context.Context, topic string, language string) (string, error)
+	// the model, as logged with every attempt
+	Model() string
+}
+
+// OpenAI is the provider the game is played with.
+type OpenAI struct{}
+
+func GetProvider() Provider {
+	return OpenAI{}
+}
+
+func (OpenAI) Stream(ctx context.Context, input string, language string, output chan string) (string, error) {
+	return StreamingLLM(input, language, ctx, output)
+}
+
+func (OpenAI) Model() string {
+	return string(MODEL)
+}
+
+func (OpenAI) Write(ctx context.Context, topic string, language string) (string, error) {
+	return WritePassage(ctx, topic, language)
+}
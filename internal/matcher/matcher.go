@@ -0,0 +1,314 @@
+package matcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/nearmiss"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	STEMMER_URL = "http://localhost:8000/stem"
+	// a hung stemmer fails the attempt instead of stalling it
+	STEMMER_TIMEOUT = 5 * time.Second
+)
+
+// Stemmer reduces words of a language to the form passage words and
+// challenge words are compared in. The returned slice is index-aligned with
+// words.
+type Stemmer interface {
+	Stem(words []string, language string) ([]string, error)
+}
+
+// ChallengeStemmer is a Stemmer that remembers the stems of challenge
+// words, they are the same for every attempt at a challenge.
+type ChallengeStemmer interface {
+	Stemmer
+	StemChallenge(challenge m.Challenge) ([]string, error)
+}
+
+// RemoteStemmer stems words through the lemmaSearch microservice.
+type RemoteStemmer struct {
+	URL    string
+	Client *http.Client
+	// challenge words and language to their stems
+	challenges sync.Map
+}
+
+func GetStemmer() Stemmer {
+	return &RemoteStemmer{URL: STEMMER_URL, Client: &http.Client{Timeout: STEMMER_TIMEOUT}}
+}
+
+// StemChallenge stems the words of a challenge once.
+func (r *RemoteStemmer) StemChallenge(challenge m.Challenge) ([]string, error) {
+	key := challenge.GetLanguage() + "\x00" + strings.Join(challenge.Words, " ")
+	if stems, ok := r.challenges.Load(key); ok {
+		return stems.([]string), nil
+	}
+	stems, err := r.Stem(challenge.Words, challenge.GetLanguage())
+	if err != nil {
+		return nil, err
+	}
+	r.challenges.Store(key, stems)
+	return stems, nil
+}
+
+// StemChallenge stems the words of a challenge, from the stemmer's cache when
+// it keeps one.
+func StemChallenge(stemmer Stemmer, challenge m.Challenge) ([]string, error) {
+	if cs, ok := stemmer.(ChallengeStemmer); ok {
+		return cs.StemChallenge(challenge)
+	}
+	return stemmer.Stem(challenge.Words, challenge.GetLanguage())
+}
+
+func (r *RemoteStemmer) Stem(words []string, language string) ([]string, error) {
+	if len(words) == 0 {
+		return nil, nil
+	}
+	reqBody := struct {
+		Words    []string `json:"words"`
+		Language string   `json:"language"`
+	}{words, language}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: STEMMER_TIMEOUT}
+	}
+	resp, err := client.Post(r.URL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stemmer responded with %s", resp.Status)
+	}
+
+	var stemResp struct {
+		Stems []string `json:"stems"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stemResp); err != nil {
+		return nil, err
+	}
+	if len(stemResp.Stems) != len(words) {
+		return nil, fmt.Errorf("stemmer returned %d stems for %d words", len(stemResp.Stems), len(words))
+	}
+	return stemResp.Stems, nil
+}
+
+// LocalStemmer strips a few common suffixes without the microservice. It is
+// cruder than the real stemmers, good enough for offline simulations.
+type LocalStemmer struct{}
+
+var localSuffixes = map[string][]string{
+	"en": {"ing", "ed", "es", "ly", "s"},
+	"es": {"es", "s"},
+}
+
+func (LocalStemmer) Stem(words []string, language string) ([]string, error) {
+	stems := make([]string, len(words))
+	for i, word := range words {
+		stem := strings.ToLower(word)
+		for _, suffix := range localSuffixes[language] {
+			// keep at least three letters of the word
+			if base, ok := strings.CutSuffix(stem, suffix); ok && utf8.RuneCountInString(base) >= 3 {
+				stem = base
+				break
+			}
+		}
+		stems[i] = stem
+	}
+	return stems, nil
+}
+
+// Matcher finds challenge words in a passage while it is still streaming.
+// Only words followed by whitespace are matched on Write, the trailing
+// partial word is held back until the next chunk or Close.
+type Matcher struct {
+	stemmer  Stemmer
+	policy   m.MatchPolicy
+	options  tokenizer.Options
+	language string
+	// challenge indices of each stem in quote order, and how often the
+	// passage had it so far
+	repeats map[string][]int
+	counts  map[string]int
+	found   []bool
+	matches []m.WordMatch
+	// words close to missing challenge words, and how many were taken
+	detector *nearmiss.Detector
+	close    []m.NearMiss
+	taken    int
+	pending  string
+	// runes of the passage already matched
+	offset int
+}
+
+// New stems the challenge words once and starts from the given progress, so
+// indices reported by Write and Close are only the ones not found before.
+func New(stemmer Stemmer, challenge m.Challenge, progress []bool) (*Matcher, error) {
+	targets, err := StemChallenge(stemmer, challenge)
+	if err != nil {
+		return nil, err
+	}
+	repeats := make(map[string][]int)
+	for i, target := range targets {
+		repeats[target] = append(repeats[target], i)
+	}
+	found := make([]bool, len(challenge.Words))
+	copy(found, progress)
+	return &Matcher{
+		detector: nearmiss.New(challenge, targets),
+		stemmer:  stemmer,
+		policy:   challenge.Matching,
+		options:  challenge.TokenizerOptions(),
+		language: challenge.GetLanguage(),
+		repeats:  repeats,
+		counts:   make(map[string]int),
+		found:    found,
+	}, nil
+}
+
+// Write consumes the next chunk of the passage and returns the challenge
+// indices newly matched by the words it completed.
+func (mt *Matcher) Write(chunk string) ([]int, error) {
+	mt.pending += chunk
+	cut := strings.LastIndexFunc(mt.pending, unicode.IsSpace)
+	if cut < 0 {
+		return nil, nil
+	}
+	// keep everything after the last whitespace, it may continue in the next chunk
+	_, size := utf8.DecodeRuneInString(mt.pending[cut:])
+	complete := mt.pending[:cut+size]
+	mt.pending = mt.pending[cut+size:]
+	return mt.match(complete)
+}
+
+// Close matches whatever is left of the passage.
+func (mt *Matcher) Close() ([]int, error) {
+	rest := mt.pending
+	mt.pending = ""
+	return mt.match(rest)
+}
+
+// Matches lists every passage word matched so far, including words whose
+// challenge word was already found before this passage.
+func (mt *Matcher) Matches() []m.WordMatch {
+	return mt.matches
+}
+
+// NearMisses lists the close words of the passage so far whose challenge
+// word is still not found.
+func (mt *Matcher) NearMisses() []m.NearMiss {
+	var misses []m.NearMiss
+	for _, miss := range mt.close {
+		if !mt.found[miss.Target] {
+			misses = append(misses, miss)
+		}
+	}
+	return misses
+}
+
+// TakeNearMisses returns the near misses added since it was last called.
+func (mt *Matcher) TakeNearMisses() []m.NearMiss {
+	var misses []m.NearMiss
+	for _, miss := range mt.close[mt.taken:] {
+		if !mt.found[miss.Target] {
+			misses = append(misses, miss)
+		}
+	}
+	mt.taken = len(mt.close)
+	return misses
+}
+
+func (mt *Matcher) addNearMisses(misses []m.NearMiss) {
+	for _, miss := range misses {
+		if !slices.Contains(mt.close, miss) {
+			mt.close = append(mt.close, miss)
+		}
+	}
+}
+
+// Found is the progress including every word matched so far.
+func (mt *Matcher) Found() []bool {
+	found := make([]bool, len(mt.found))
+	copy(found, mt.found)
+	return found
+}
+
+func (mt *Matcher) match(text string) ([]int, error) {
+	tokens := tokenizer.Tokenize(text, mt.options)
+	offset := mt.offset
+	if len(tokens) == 0 {
+		mt.offset += utf8.RuneCountInString(text)
+		return nil, nil
+	}
+	stems, err := mt.stemmer.Stem(tokenizer.Words(tokens), mt.language)
+	if err != nil {
+		return nil, err
+	}
+	mt.offset += utf8.RuneCountInString(text)
+
+	var matched []int
+	for j, stem := range stems {
+		satisfied := mt.satisfies(stem)
+		if len(satisfied) == 0 {
+			mt.addNearMisses(mt.detector.Check(tokens[j].Word, stem, mt.found))
+		}
+		for _, i := range satisfied {
+			mt.matches = append(mt.matches, m.WordMatch{
+				Start:  offset + tokens[j].Start,
+				End:    offset + tokens[j].End,
+				Target: i,
+			})
+			if !mt.found[i] {
+				mt.found[i] = true
+				matched = append(matched, i)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// satisfies returns the challenge indices the next passage word with this
+// stem counts for.
+func (mt *Matcher) satisfies(stem string) []int {
+	indices := mt.repeats[stem]
+	if mt.policy != m.MatchToken {
+		return indices
+	}
+	// the k-th occurrence in the passage satisfies the k-th in the quote
+	mt.counts[stem]++
+	if k := mt.counts[stem]; k <= len(indices) {
+		return indices[k-1 : k]
+	}
+	return nil
+}
+
+// Match runs a whole passage through a Matcher and returns the resulting progress.
+func Match(stemmer Stemmer, content string, challenge m.Challenge, progress []bool) ([]bool, error) {
+	mt, err := New(stemmer, challenge, progress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mt.Write(content); err != nil {
+		return nil, err
+	}
+	if _, err := mt.Close(); err != nil {
+		return nil, err
+	}
+	return mt.Found(), nil
+}
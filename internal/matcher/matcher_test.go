@@ -0,0 +1,197 @@
+package matcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+// trims a plural "s" so tests don't need the lemmaSearch service
+type suffixStemmer struct{}
+
+func (suffixStemmer) Stem(words []string, language string) ([]string, error) {
+	stems := make([]string, len(words))
+	for i, word := range words {
+		stems[i] = strings.TrimSuffix(strings.ToLower(word), "s")
+	}
+	return stems, nil
+}
+
+var (
+	quote   = m.Challenge{Words: []string{"the", "future", "belongs", "to", "those", "who", "believe", "in", "dreams"}}
+	passage = "Those who dream  big\tbelieve the Future belong’s to them,\nin time."
+)
+
+func TestMatchWholePassage(t *testing.T) {
+	found, err := Match(suffixStemmer{}, passage, quote, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bool{true, true, true, true, true, true, true, true, true}
+	if !reflect.DeepEqual(found, want) {
+		t.Fatalf("got %v, want %v", found, want)
+	}
+}
+
+func TestIncrementalMatchesWholePassage(t *testing.T) {
+	want, err := Match(suffixStemmer{}, passage, quote, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every split point, including ones inside words and multi-byte runes
+	for i := 0; i <= len(passage); i++ {
+		for j := i; j <= len(passage); j++ {
+			mt, err := New(suffixStemmer{}, quote, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := make(map[int]bool)
+			for _, chunk := range []string{passage[:i], passage[i:j], passage[j:]} {
+				matched, err := mt.Write(chunk)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, idx := range matched {
+					if seen[idx] {
+						t.Fatalf("split %d/%d: index %d reported twice", i, j, idx)
+					}
+					seen[idx] = true
+				}
+			}
+			matched, err := mt.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, idx := range matched {
+				seen[idx] = true
+			}
+			if got := mt.Found(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("split %d/%d: got %v, want %v", i, j, got, want)
+			}
+			if len(seen) != len(quote.Words) {
+				t.Fatalf("split %d/%d: reported %d indices, want %d", i, j, len(seen), len(quote.Words))
+			}
+		}
+	}
+}
+
+func TestWriteHoldsPartialWord(t *testing.T) {
+	mt, err := New(suffixStemmer{}, m.Challenge{Words: []string{"dreams"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched, _ := mt.Write("big dre"); len(matched) != 0 {
+		t.Fatalf("matched %v before the word was complete", matched)
+	}
+	if matched, _ := mt.Write("am "); !reflect.DeepEqual(matched, []int{0}) {
+		t.Fatalf("got %v, want [0]", matched)
+	}
+}
+
+func TestPriorProgressIsNotReported(t *testing.T) {
+	mt, err := New(suffixStemmer{}, m.Challenge{Words: []string{"hope", "light"}}, []bool{true, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched, _ := mt.Write("hope and light ")
+	if !reflect.DeepEqual(matched, []int{1}) {
+		t.Fatalf("got %v, want [1]", matched)
+	}
+	if found := mt.Found(); !reflect.DeepEqual(found, []bool{true, true}) {
+		t.Fatalf("got %v", found)
+	}
+}
+
+func TestMatchSpans(t *testing.T) {
+	text := "Don’t dream; believe in dreams."
+	words := []string{"dreams", "believe"}
+	mt, err := New(suffixStemmer{}, m.Challenge{Words: words}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range text {
+		if _, err := mt.Write(string(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mt.Close()
+
+	runes := []rune(text)
+	var got []string
+	for _, match := range mt.Matches() {
+		got = append(got, string(runes[match.Start:match.End])+"->"+words[match.Target])
+	}
+	want := []string{"dream->dreams", "believe->believe", "dreams->dreams"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRepeatedWords(t *testing.T) {
+	words := []string{"the", "light", "and", "the", "dark", "the"}
+	tests := []struct {
+		policy  m.MatchPolicy
+		passage string
+		want    []bool
+	}{
+		{m.MatchType, "the light", []bool{true, true, false, true, false, true}},
+		{m.MatchToken, "the light", []bool{true, true, false, false, false, false}},
+		{m.MatchToken, "the light of the day", []bool{true, true, false, true, false, false}},
+		{m.MatchToken, "the the the the", []bool{true, false, false, true, false, true}},
+	}
+	for _, tt := range tests {
+		challenge := m.Challenge{Words: words, Matching: tt.policy}
+		got, err := Match(suffixStemmer{}, tt.passage, challenge, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q: got %v, want %v", tt.policy, tt.passage, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStemmer(t *testing.T) {
+	got, _ := LocalStemmer{}.Stem([]string{"Dreams", "hoping", "is", "walked", "flores"}, "en")
+	want := []string{"dream", "hop", "is", "walk", "flor"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRemoteStemmer(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req struct {
+			Words []string `json:"words"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Words[0] == "hang" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		stems, _ := suffixStemmer{}.Stem(req.Words, "en")
+		json.NewEncoder(w).Encode(map[string][]string{"stems": stems})
+	}))
+	defer server.Close()
+	stemmer := &RemoteStemmer{URL: server.URL, Client: &http.Client{Timeout: 50 * time.Millisecond}}
+
+	// the quote is stemmed once however many attempts there are
+	for range 3 {
+		if _, err := New(stemmer, quote, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("quote stemmed %d times, want once", calls)
+	}
+	if _, err := stemmer.Stem([]string{"hang"}, "en"); err == nil {
+		t.Error("a hung stemmer should time out")
+	}
+}
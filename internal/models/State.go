@@ -1,51 +1,231 @@
 package models
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"errors"
+	"math"
 	"time"
+
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
 )
 
 var (
 	INACTIVE_THRESHOLD = time.Minute * 10
+	STOPWORD_WEIGHT    = 0.25
+	DEFAULT_LANGUAGE   = "en"
 )
 
+// Entry is a generated passage, a node in the tree of attempts. Parent is
+// the ID of the entry whose passage the input was picked from, -1 for the
+// challenge's starting content. Branch numbers the lines of play, a branch
+// starts whenever an entry that already has a child is continued again.
 type Entry struct {
-	Input   string `json:"input"`
-	Content string `json:"content"`
+	ID      int         `json:"id"`
+	Parent  int         `json:"parent"`
+	Branch  int         `json:"branch"`
+	Input   string      `json:"input"`
+	Content string      `json:"content"`
+	Matches []WordMatch `json:"matches,omitempty"`
+	Close   []NearMiss  `json:"close,omitempty"`
+}
+
+// WordMatch is a word of a passage that satisfied the challenge word at
+// index Target. Start and End are rune offsets into the passage.
+type WordMatch struct {
+	Start  int `json:"start"`
+	End    int `json:"end"`
+	Target int `json:"target"`
+}
+
+// NearMiss is a passage word that came close to the challenge word at
+// index Target without matching it. Reason is "related", "prefix" or
+// "spelling".
+type NearMiss struct {
+	Target int    `json:"target"`
+	Word   string `json:"word"`
+	Reason string `json:"reason"`
 }
 
 type State struct {
-	ID           string    `json:"id"`
-	Challenge    int       `json:"challenge"`
-	Progress     []bool    `json:"progress"`
-	Content      []Entry   `json:"content"`
-	Attempts     int       `json:"attempts"`
+	ID string `json:"id"`
+	// long-lived identity of the player, stats are kept per player
+	Player string `json:"player"`
+	// IANA name of the player's timezone, where their days start and end
+	Timezone string `json:"timezone"`
+	// YYYY-MM-DD of the day's challenges being played
+	Day string `json:"day"`
+	// replaying an archived day, which doesn't count for streaks or boards
+	Practice  bool    `json:"practice"`
+	Challenge int     `json:"challenge"`
+	Language  string  `json:"language"`
+	Progress  []bool  `json:"progress"`
+	Content   []Entry `json:"content"`
+	// ID of the entry the player picks words from, -1 before the first attempt
+	Head     int    `json:"head"`
+	Attempts int    `json:"attempts"`
+	Hints    []Hint `json:"hints"`
+	Mode     string `json:"mode"`
+	// when the challenge was started, and when it was solved or ran out
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// challenges of the day already played, oldest first
+	Results      []Result  `json:"results"`
 	LastAccessed time.Time `json:"lastaccessed"`
 }
 
+// Result is what is left of a challenge once the player moved on, enough
+// to share it without giving the quote away.
+type Result struct {
+	Challenge   int    `json:"challenge"`
+	Mode        string `json:"mode"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"maxAttempts"`
+	// attempts that generated a passage, the others went to hints
+	Passages int `json:"passages"`
+	// challenge words each attempt found first, in the order they were made
+	Unlocked []int `json:"unlocked"`
+	Hints    int   `json:"hints"`
+	Solved   bool  `json:"solved"`
+	Score    int   `json:"score"`
+	// the archived day replayed, empty for the daily challenges
+	Practice string `json:"practice,omitempty"`
+}
+
+const (
+	// the first letter of a challenge word
+	HINT_LETTER = "letter"
+	// words of the current passage that lead toward a challenge word
+	HINT_LEAD = "lead"
+	// the challenge word itself, which counts as found
+	HINT_REVEAL = "reveal"
+)
+
+// HintCost is what a hint tier takes from the player.
+type HintCost struct {
+	Attempts int `json:"attempts"`
+	Points   int `json:"points"`
+}
+
+// Hint records a hint taken for the challenge word at index Target.
+type Hint struct {
+	Target int       `json:"target"`
+	Tier   string    `json:"tier"`
+	Cost   HintCost  `json:"cost"`
+	Time   time.Time `json:"time"`
+}
+
+// addContent attaches the entry below the head and moves the head to it.
 func (s *State) addContent(content Entry) {
-	if s.Attempts >= len(s.Content) {
-		panic("Max Attempts reached. Should Not be able to call AddContent")
+	content.ID = len(s.Content)
+	content.Parent = s.Head
+	content.Branch = 0
+	if s.Head >= 0 {
+		content.Branch = s.Content[s.Head].Branch
+	}
+	for _, entry := range s.Content {
+		if entry.Parent == s.Head {
+			// the head was continued before, this is a new line of play
+			content.Branch = s.branches()
+			break
+		}
 	}
 	s.Content = append(s.Content, content)
+	s.Head = content.ID
 	s.Attempts++
-	return
+}
+
+func (s *State) branches() int {
+	n := 0
+	for _, entry := range s.Content {
+		n = max(n, entry.Branch+1)
+	}
+	return n
+}
+
+// Current is the entry at the head, nil before the first attempt.
+func (s *State) Current() *Entry {
+	if s.Head < 0 || s.Head >= len(s.Content) {
+		return nil
+	}
+	return &s.Content[s.Head]
+}
+
+// Rewind moves the head back to an earlier entry, or to the starting
+// content with -1. Attempts already spent stay spent.
+func (s *State) Rewind(id int) error {
+	if id < -1 || id >= len(s.Content) {
+		return errors.New("no such entry")
+	}
+	s.Head = id
+	return nil
+}
+
+// SpendHint charges the cost of a hint to the attempts and records it.
+func (s *State) SpendHint(target int, tier string, cost HintCost) {
+	s.Attempts += cost.Attempts
+	s.Hints = append(s.Hints, Hint{Target: target, Tier: tier, Cost: cost, Time: time.Now()})
+	if tier == HINT_REVEAL {
+		s.Progress[target] = true
+	}
+}
+
+// HintPoints is the score lost to hints.
+func (s *State) HintPoints() int {
+	points := 0
+	for _, hint := range s.Hints {
+		points += hint.Cost.Points
+	}
+	return points
+}
+
+// Unlocked counts the challenge words each attempt found that no earlier
+// attempt had, words in given don't count.
+func (s *State) Unlocked(given []bool) []int {
+	found := make(map[int]bool)
+	for i := range given {
+		found[i] = given[i]
+	}
+	unlocked := make([]int, len(s.Content))
+	for i, entry := range s.Content {
+		for _, match := range entry.Matches {
+			if !found[match.Target] {
+				found[match.Target] = true
+				unlocked[i]++
+			}
+		}
+	}
+	return unlocked
+}
+
+// Solved is true once every challenge word is found.
+func (s *State) Solved() bool {
+	for i := range s.Progress {
+		if !s.Progress[i] {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *State) IsActive() bool {
 	return time.Since(s.LastAccessed) < INACTIVE_THRESHOLD
 }
 
-// Not Used Yet
-func (s *State) Validate(Input string) bool {
+// Validate checks the input only uses words of the current passage, or of
+// the starting content before the first attempt, split the way the
+// challenge splits them.
+func (s *State) Validate(challenge Challenge, input string) bool {
 	freq := make(map[string]int)
-	content := SanitizeAndSplit(s.Content[len(s.Content)-1].Content)
+	passage := challenge.Content
+	if current := s.Current(); current != nil {
+		passage = current.Content
+	}
+	options := challenge.TokenizerOptions()
+	content := tokenizer.Words(tokenizer.Tokenize(passage, options))
 	for i := range content {
 		freq[content[i]]++
 	}
-	for _, word := range SanitizeAndSplit(Input) {
+	for _, word := range tokenizer.Words(tokenizer.Tokenize(input, options)) {
 		freq[word]--
 		if freq[word] < 0 {
 			return false
@@ -54,81 +234,143 @@ func (s *State) Validate(Input string) bool {
 	return true
 }
 
-func (s *State) UpdateSession(input string, content string, challengewords []string) error {
-	entry := Entry{Input: input, Content: content}
-	s.addContent(entry)
-	err := s.findCommonWords(entry.Content, challengewords)
-	if err != nil {
-		return err
-	}
-	return nil
+// UpdateSession records the passage generated for input along with the
+// progress the matcher computed for it.
+func (s *State) UpdateSession(input string, content string, progress []bool, matches []WordMatch, near []NearMiss) {
+	s.addContent(Entry{Input: input, Content: content, Matches: matches, Close: near})
+	copy(s.Progress, progress)
 }
 
-func (s *State) findCommonWords(content string, challengewords []string) error {
-	words := SanitizeAndSplit(content)
-	reqBody := struct {
-		Content        []string `json:"content"`
-		ChallengeWords []string `json:"challenge_words"`
-	}{words, challengewords}
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return err
-	}
-	resp, err := http.Post(
-		"http://localhost:8000/find-common-words",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+// MatchPolicy decides how repeated words of a quote are satisfied.
+type MatchPolicy string
 
-	// Parse the response
-	var stemResp struct {
-		MatchedIndices [][]int `json:"matched_indices"`
-	}
+const (
+	// one occurrence in a passage satisfies every repeat of the word
+	MatchType MatchPolicy = "type"
+	// the passage needs the word as many times as the quote has it
+	MatchToken MatchPolicy = "token"
+)
+
+// StopwordPolicy decides how quote words like "the" or "to" count.
+type StopwordPolicy string
+
+const (
+	// stopwords are given to the player and do not count for the score
+	StopwordsExclude StopwordPolicy = "exclude"
+	// stopwords must still be found but weigh less in the score
+	StopwordsDownweight StopwordPolicy = "downweight"
+)
+
+type Challenge struct {
+	// set once the challenge is stored, Date is the YYYY-MM-DD it is played
+	// on and Ordinal its place among that day's challenges of its language
+	ID        int
+	Date      string
+	Ordinal   int
+	Quote     string
+	Author    string
+	Content   string
+	Words     []string
+	Matching  MatchPolicy
+	Stopwords StopwordPolicy
+	Tokenizer tokenizer.Options
+	// ISO 639-1 code, picks the stemmer, stopwords, tokenizer rules and prompt
+	Language string
+	// label from the challenge pack, "easy", "medium" or "hard"
+	Difficulty string
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&stemResp); err != nil {
-		return err
+func (c Challenge) GetLanguage() string {
+	if c.Language == "" {
+		return DEFAULT_LANGUAGE
 	}
+	return c.Language
+}
 
-	for _, match := range stemResp.MatchedIndices {
-		challengeIdx := match[1]
-		s.Progress[challengeIdx] = true
+func (c Challenge) TokenizerOptions() tokenizer.Options {
+	opts := c.Tokenizer
+	opts.Language = c.GetLanguage()
+	return opts
+}
+
+// Tokens splits the quote into the challenge words.
+func (c Challenge) Tokens() []tokenizer.Token {
+	return tokenizer.Tokenize(c.Quote, c.TokenizerOptions())
+}
+
+// Given marks the words that start out satisfied.
+func (c Challenge) Given() []bool {
+	given := make([]bool, len(c.Words))
+	if c.Stopwords != StopwordsExclude {
+		return given
+	}
+	set := stopwords.Get(c.GetLanguage())
+	for i, word := range c.Words {
+		given[i] = set.Contains(word)
 	}
+	return given
+}
 
-	return nil
+// Weights is how much each word is worth towards the score.
+func (c Challenge) Weights() []float64 {
+	weights := make([]float64, len(c.Words))
+	set := stopwords.Get(c.GetLanguage())
+	for i, word := range c.Words {
+		weights[i] = 1
+		if !set.Contains(word) {
+			continue
+		}
+		switch c.Stopwords {
+		case StopwordsExclude:
+			weights[i] = 0
+		case StopwordsDownweight:
+			weights[i] = STOPWORD_WEIGHT
+		}
+	}
+	return weights
 }
 
-type Challenge struct {
-	Quote   string
-	Author  string
-	Content string
-	Words   []string
+// Score is the weighted share of words found, from 0 to 100.
+func (c Challenge) Score(progress []bool) int {
+	var found, total float64
+	for i, weight := range c.Weights() {
+		total += weight
+		if i < len(progress) && progress[i] {
+			found += weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * found / total))
 }
 
 func GetChallenges() []Challenge {
 	quotes := []struct {
-		quote, author, content string
+		quote, author, content, language string
 	}{
-		{"When you reach the end of your rope, tie a knot in it and hang on.", "Franklin D. Roosevelt", "Persistence and resilience are key to overcoming obstacles. Success often comes to those who refuse to give up, learning from failures and adapting to new challenges. Each setback is an opportunity to grow stronger and wiser."},
-		{"Always remember that you are absolutely unique. Just like everyone else.", "Margaret Mead", "Individuality defines us, yet we share common traits that bind humanity together. Embracing our uniqueness while understanding others fosters both personal growth and stronger communities."},
-		{"Don't judge each day by the harvest you reap but by the seeds that you plant.", "Robert Louis Stevenson", "Progress is often measured by the effort we invest rather than immediate outcomes. Small, consistent actions lead to significant achievements over time, reinforcing the value of patience and dedication."},
-		{"The future belongs to those who believe in the beauty of their dreams.", "Eleanor Roosevelt", "Visionaries shape the world by daring to dream big. Their belief fuels innovation and perseverance, transforming ideas into reality. Confidence in one’s aspirations is the first step toward success."},
-		{"It is during our darkest moments that we must focus to see the light.", "Aristotle", "Challenges test our resolve, but hope and determination guide us forward. Strength emerges from adversity, and maintaining a positive outlook is crucial to navigating life’s uncertainties."},
+		{"When you reach the end of your rope, tie a knot in it and hang on.", "Franklin D. Roosevelt", "Persistence and resilience are key to overcoming obstacles. Success often comes to those who refuse to give up, learning from failures and adapting to new challenges. Each setback is an opportunity to grow stronger and wiser.", "en"},
+		{"Always remember that you are absolutely unique. Just like everyone else.", "Margaret Mead", "Individuality defines us, yet we share common traits that bind humanity together. Embracing our uniqueness while understanding others fosters both personal growth and stronger communities.", "en"},
+		{"Don't judge each day by the harvest you reap but by the seeds that you plant.", "Robert Louis Stevenson", "Progress is often measured by the effort we invest rather than immediate outcomes. Small, consistent actions lead to significant achievements over time, reinforcing the value of patience and dedication.", "en"},
+		{"The future belongs to those who believe in the beauty of their dreams.", "Eleanor Roosevelt", "Visionaries shape the world by daring to dream big. Their belief fuels innovation and perseverance, transforming ideas into reality. Confidence in one’s aspirations is the first step toward success.", "en"},
+		{"It is during our darkest moments that we must focus to see the light.", "Aristotle", "Challenges test our resolve, but hope and determination guide us forward. Strength emerges from adversity, and maintaining a positive outlook is crucial to navigating life’s uncertainties.", "en"},
+		{"Caminante, no hay camino, se hace camino al andar.", "Antonio Machado", "Los viajeros descubren que cada destino se construye paso a paso. Ningún mapa muestra la ruta completa; la experiencia, la paciencia y la curiosidad abren senderos nuevos donde antes solo había montañas y dudas.", "es"},
+		{"El que lee mucho y anda mucho, ve mucho y sabe mucho.", "Miguel de Cervantes", "Las bibliotecas y los viajes amplían la mente de maneras distintas. Un libro nos lleva a épocas lejanas, mientras que un camino desconocido nos enseña costumbres, idiomas y sabores que ninguna página puede describir.", "es"},
+		{"Podrán cortar todas las flores, pero no podrán detener la primavera.", "Pablo Neruda", "Los jardines resisten el invierno bajo la tierra. Las semillas esperan en silencio hasta que el sol regresa, y entonces los campos se llenan de colores, insectos y aromas que anuncian una estación nueva.", "es"},
+		{"La vida es sueño, y los sueños, sueños son.", "Pedro Calderón de la Barca", "Los científicos estudian la memoria y la imaginación durante la noche. Mientras dormimos, el cerebro ordena recuerdos, mezcla deseos y crea historias extrañas que al despertar parecen tan reales como el día.", "es"},
 	}
 
 	var challenges []Challenge
 	for _, q := range quotes {
-		challenges = append(challenges, Challenge{
-			Quote:   q.quote,
-			Author:  q.author,
-			Content: q.content,
-			Words:   SanitizeAndSplit(q.quote),
-		})
+		challenge := Challenge{
+			Quote:    q.quote,
+			Author:   q.author,
+			Content:  q.content,
+			Language: q.language,
+		}
+		challenge.Words = tokenizer.Words(challenge.Tokens())
+		challenges = append(challenges, challenge)
 	}
 
 	return challenges
 }
-
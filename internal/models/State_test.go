@@ -0,0 +1,123 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+func TestStopwordPolicies(t *testing.T) {
+	words := SanitizeAndSplit("The future belongs to those who believe")
+	progress := []bool{true, true, false, false, false, false, true}
+
+	tests := []struct {
+		policy StopwordPolicy
+		given  []bool
+		score  int
+	}{
+		{"", []bool{false, false, false, false, false, false, false}, 43},
+		{StopwordsExclude, []bool{true, false, false, true, true, true, false}, 67},
+		{StopwordsDownweight, []bool{false, false, false, false, false, false, false}, 56},
+	}
+	for _, tt := range tests {
+		c := Challenge{Words: words, Stopwords: tt.policy}
+		if given := c.Given(); !reflect.DeepEqual(given, tt.given) {
+			t.Errorf("%q: given %v, want %v", tt.policy, given, tt.given)
+		}
+		if score := c.Score(progress); score != tt.score {
+			t.Errorf("%q: score %d, want %d", tt.policy, score, tt.score)
+		}
+	}
+}
+
+func TestAttemptTree(t *testing.T) {
+	s := &State{Head: -1, Progress: []bool{false}}
+	s.UpdateSession("a", "first", nil, nil, nil)
+	s.UpdateSession("b", "second", nil, nil, nil)
+	if err := s.Rewind(0); err != nil {
+		t.Fatal(err)
+	}
+	s.UpdateSession("c", "third", nil, nil, nil)
+	s.UpdateSession("d", "fourth", nil, nil, nil)
+	if err := s.Rewind(-1); err != nil {
+		t.Fatal(err)
+	}
+	s.UpdateSession("e", "fifth", nil, nil, nil)
+
+	type node struct{ id, parent, branch int }
+	want := []node{{0, -1, 0}, {1, 0, 0}, {2, 0, 1}, {3, 2, 1}, {4, -1, 2}}
+	for i, entry := range s.Content {
+		if got := (node{entry.ID, entry.Parent, entry.Branch}); got != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+	if s.Attempts != 5 || s.Current().Content != "fifth" {
+		t.Errorf("attempts %d, head %q", s.Attempts, s.Current().Content)
+	}
+	if err := s.Rewind(5); err == nil {
+		t.Error("rewinding to a missing entry should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := &State{Head: -1}
+	s.UpdateSession("", "Their well-being doesn't wait.", nil, nil, nil)
+	keep := Challenge{Tokenizer: tokenizer.Options{Hyphens: tokenizer.HyphenKeep, Contractions: tokenizer.ContractionKeep}}
+
+	tests := []struct {
+		challenge Challenge
+		input     string
+		valid     bool
+	}{
+		{Challenge{}, "well being", true},
+		{Challenge{}, "does not wait", true},
+		{Challenge{}, "wait wait", false},
+		{keep, "well-being doesn’t", true},
+		{keep, "well", false},
+		{keep, "does not", false},
+	}
+	for _, tt := range tests {
+		if valid := s.Validate(tt.challenge, tt.input); valid != tt.valid {
+			t.Errorf("%+v %q: got %v, want %v", tt.challenge.Tokenizer, tt.input, valid, tt.valid)
+		}
+	}
+
+	// before the first attempt the starting content is the passage
+	start := Challenge{Content: "A rope tied in a knot."}
+	if fresh := (&State{Head: -1}); !fresh.Validate(start, "rope knot") || fresh.Validate(start, "rope ladder") {
+		t.Error("input should be checked against the starting content")
+	}
+}
+
+func TestSpendHint(t *testing.T) {
+	costs := map[string]HintCost{
+		HINT_LETTER: {Attempts: 1},
+		HINT_LEAD:   {Attempts: 2},
+		HINT_REVEAL: {Attempts: 3, Points: 10},
+	}
+	tests := []struct {
+		tiers    []string
+		attempts int
+		points   int
+		progress []bool
+	}{
+		{nil, 0, 0, []bool{false, false}},
+		{[]string{HINT_LETTER}, 1, 0, []bool{false, false}},
+		{[]string{HINT_LETTER, HINT_LEAD}, 3, 0, []bool{false, false}},
+		{[]string{HINT_REVEAL}, 3, 10, []bool{true, false}},
+		{[]string{HINT_LEAD, HINT_REVEAL, HINT_REVEAL}, 8, 20, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		s := &State{Head: -1, Progress: []bool{false, false}}
+		for _, tier := range tt.tiers {
+			s.SpendHint(0, tier, costs[tier])
+		}
+		if s.Attempts != tt.attempts || s.HintPoints() != tt.points || len(s.Hints) != len(tt.tiers) {
+			t.Errorf("%v: attempts %d, points %d, hints %d", tt.tiers, s.Attempts, s.HintPoints(), len(s.Hints))
+		}
+		if !reflect.DeepEqual(s.Progress, tt.progress) {
+			t.Errorf("%v: progress %v, want %v", tt.tiers, s.Progress, tt.progress)
+		}
+	}
+}
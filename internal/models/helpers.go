@@ -1,40 +1,89 @@
 package models
 
 import (
-	"strings"
-	"unicode"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
 )
 
 // get Request response
 type ResponseStruct struct {
+	Day       string `json:"day"`
+	Practice  bool   `json:"practice"`
 	Challenge int    `json:"challenge"`
+	Language  string `json:"language"`
 	Quote     string `json:"quote"`
-	Author    string `json:"author"`
-	Content   string `json:"content"`
-	Attempts  int    `json:"attempts"`
-	Progress  []bool `json:"progress"`
+	// the challenge words with their spans in Quote, index-aligned with Progress
+	Words   []tokenizer.Token `json:"words"`
+	Author  string            `json:"author"`
+	Content string            `json:"content"`
+	// every attempt so far as a tree, and the entry Content comes from
+	Tree     []Entry `json:"tree"`
+	Head     int     `json:"head"`
+	Attempts int     `json:"attempts"`
+	// -1 when the mode allows unlimited attempts
+	AttemptsLeft int        `json:"attemptsLeft"`
+	Mode         Mode       `json:"mode"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+	Progress     []bool     `json:"progress"`
+	// words that were satisfied from the start, like excluded stopwords
+	Given []bool `json:"given"`
+	Score int    `json:"score"`
+	Hints []Hint `json:"hints"`
+	// spans of the challenge words found in Content
+	Matches []WordMatch `json:"matches"`
+	// words of Content close to challenge words that are still missing
+	Close []NearMiss `json:"close"`
+}
+
+// POST /game/hint response
+type HintResponse struct {
+	Target   int      `json:"target"`
+	Tier     string   `json:"tier"`
+	Letter   string   `json:"letter,omitempty"`
+	Leads    []string `json:"leads,omitempty"`
+	Word     string   `json:"word,omitempty"`
+	Cost     HintCost `json:"cost"`
+	Attempts int      `json:"attempts"`
+	Progress []bool   `json:"progress"`
+}
+
+// POST /game?events=1 response, one JSON object per line
+type StreamEvent struct {
+	Chunk    string      `json:"chunk,omitempty"`
+	Matched  []int       `json:"matched,omitempty"`
+	Progress []bool      `json:"progress,omitempty"`
+	Matches  []WordMatch `json:"matches,omitempty"`
+	Close    []NearMiss  `json:"close,omitempty"`
 }
 
 func (s *State) GetPayload() ResponseStruct {
 	res := ResponseStruct{
+		Day:       s.Day,
+		Practice:  s.Practice,
 		Challenge: s.Challenge,
+		Language:  s.Language,
 		Progress:  s.Progress,
 		Attempts:  s.Attempts,
+		Hints:     s.Hints,
+		Tree:      s.Content,
+		Head:      s.Head,
+		Matches:   []WordMatch{},
+		Close:     []NearMiss{},
 	}
-	if len(s.Content) != 0 {
-		res.Content = s.Content[len(s.Content)-1].Content
+	if latest := s.Current(); latest != nil {
+		res.Content = latest.Content
+		if latest.Matches != nil {
+			res.Matches = latest.Matches
+		}
+		if latest.Close != nil {
+			res.Close = latest.Close
+		}
 	}
 	return res
 }
 
+// SanitizeAndSplit tokenizes text with the default tokenizer options.
 func SanitizeAndSplit(text string) []string {
-	text = strings.ToLower(text)
-	var clean strings.Builder
-	for _, r := range text {
-		if unicode.IsLetter(r) || unicode.IsSpace(r) {
-			clean.WriteRune(r)
-		}
-	}
-	words := strings.Fields(clean.String())
-	return words
+	return tokenizer.Words(tokenizer.Tokenize(text, tokenizer.Options{}))
 }
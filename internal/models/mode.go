@@ -0,0 +1,62 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Mode is a way of playing a challenge, picked when the challenge starts.
+// It decides when the challenge is over and how it is scored.
+type Mode struct {
+	Name string `json:"name"`
+	// 0 allows unlimited attempts
+	MaxAttempts int `json:"maxAttempts"`
+	// wall-clock limit from the start of the challenge, 0 for none
+	TimeLimit time.Duration `json:"-"`
+	// challenge words may not be picked as input
+	NoTargetInput bool `json:"noTargetInput"`
+	Scored        bool `json:"scored"`
+	// multiplies the score of a challenge played in this mode
+	ScoreFactor float64 `json:"scoreFactor"`
+	// points for solving a timed challenge instantly, less the longer it took
+	TimeBonus int `json:"timeBonus"`
+}
+
+func (md Mode) OutOfAttempts(s *State) bool {
+	return md.MaxAttempts > 0 && s.Attempts >= md.MaxAttempts
+}
+
+// AttemptsLeft is how many attempts can still be spent, -1 when unlimited.
+func (md Mode) AttemptsLeft(s *State) int {
+	if md.MaxAttempts == 0 {
+		return -1
+	}
+	return max(0, md.MaxAttempts-s.Attempts)
+}
+
+// Deadline is when a timed challenge ends, the zero time otherwise.
+func (md Mode) Deadline(s *State) time.Time {
+	if md.TimeLimit == 0 {
+		return time.Time{}
+	}
+	return s.Started.Add(md.TimeLimit)
+}
+
+func (md Mode) OutOfTime(s *State, now time.Time) bool {
+	return md.TimeLimit > 0 && !now.Before(md.Deadline(s))
+}
+
+func (md Mode) Score(c Challenge, s *State) int {
+	if !md.Scored {
+		return 0
+	}
+	score := float64(max(0, c.Score(s.Progress)-s.HintPoints()))
+	if md.ScoreFactor > 0 {
+		score *= md.ScoreFactor
+	}
+	if md.TimeLimit > 0 && s.Solved() && !s.Finished.IsZero() {
+		left := md.Deadline(s).Sub(s.Finished)
+		score += float64(md.TimeBonus) * max(0, float64(left)/float64(md.TimeLimit))
+	}
+	return int(math.Round(score))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestModeScore(t *testing.T) {
+	c := Challenge{Words: SanitizeAndSplit("practice makes perfect")}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := &State{Progress: []bool{true, true, true}, Attempts: 3, Started: start, Finished: start.Add(time.Minute)}
+
+	tests := []struct {
+		name  string
+		mode  Mode
+		score int
+		left  int
+	}{
+		{"classic", Mode{MaxAttempts: 25, Scored: true}, 100, 22},
+		{"zen", Mode{}, 0, -1},
+		{"hard", Mode{MaxAttempts: 15, Scored: true, ScoreFactor: 1.5}, 150, 12},
+		{"timed", Mode{MaxAttempts: 25, Scored: true, TimeLimit: 4 * time.Minute, TimeBonus: 40}, 130, 22},
+	}
+	for _, tt := range tests {
+		if score := tt.mode.Score(c, s); score != tt.score {
+			t.Errorf("%s: score %d, want %d", tt.name, score, tt.score)
+		}
+		if left := tt.mode.AttemptsLeft(s); left != tt.left {
+			t.Errorf("%s: %d attempts left, want %d", tt.name, left, tt.left)
+		}
+	}
+
+	timed := tests[3].mode
+	if timed.OutOfTime(s, start.Add(3*time.Minute)) || !timed.OutOfTime(s, start.Add(4*time.Minute)) {
+		t.Error("timed mode should end exactly at its deadline")
+	}
+}
@@ -0,0 +1,192 @@
+package nearmiss
+
+import (
+	"bufio"
+	"embed"
+	"slices"
+	"strings"
+	"sync"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+)
+
+var (
+	// words sharing a prefix this long are close
+	MIN_PREFIX = 4
+	// words shorter than this are never close to anything
+	MIN_LENGTH = 3
+	//go:embed thesaurus
+	thesauri embed.FS
+	cache    sync.Map
+)
+
+const (
+	REASON_RELATED  = "related"
+	REASON_PREFIX   = "prefix"
+	REASON_SPELLING = "spelling"
+)
+
+// thesaurus maps a word to the ids of the groups it is listed in.
+type thesaurus map[string][]int
+
+func getThesaurus(language string) thesaurus {
+	if t, ok := cache.Load(language); ok {
+		return t.(thesaurus)
+	}
+	t := make(thesaurus)
+	file, err := thesauri.Open("thesaurus/" + language + ".txt")
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for group := 0; scanner.Scan(); group++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			for _, word := range strings.Fields(line) {
+				t[word] = append(t[word], group)
+			}
+		}
+	}
+	cache.Store(language, t)
+	return t
+}
+
+// Related lists the words the thesaurus of a language has in a group with
+// word.
+func Related(language, word string) []string {
+	t := getThesaurus(language)
+	groups := t[word]
+	var words []string
+	for other, otherGroups := range t {
+		if other == word {
+			continue
+		}
+		for _, g := range otherGroups {
+			if slices.Contains(groups, g) {
+				words = append(words, other)
+				break
+			}
+		}
+	}
+	slices.Sort(words)
+	return words
+}
+
+func (t thesaurus) related(a, b string) bool {
+	for _, x := range t[a] {
+		for _, y := range t[b] {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Detector finds passage words that are close to a challenge word without
+// matching it: listed as related in the thesaurus, sharing a long prefix or
+// a typo away.
+type Detector struct {
+	words     []string
+	stems     []string
+	skip      []bool
+	stopwords stopwords.Set
+	thesaurus thesaurus
+}
+
+// New takes the challenge and the stems its words matched against.
+func New(challenge m.Challenge, stems []string) *Detector {
+	language := challenge.GetLanguage()
+	d := &Detector{
+		words:     challenge.Words,
+		stems:     stems,
+		skip:      make([]bool, len(challenge.Words)),
+		stopwords: stopwords.Get(language),
+		thesaurus: getThesaurus(language),
+	}
+	// a word close to "the" or "to" tells the player nothing
+	for i, word := range d.words {
+		d.skip[i] = d.stopwords.Contains(word) || len([]rune(word)) < MIN_LENGTH
+	}
+	return d
+}
+
+// Check compares one passage word against the challenge words that are
+// not found yet.
+func (d *Detector) Check(word, stem string, found []bool) []m.NearMiss {
+	if len([]rune(word)) < MIN_LENGTH || d.stopwords.Contains(word) {
+		return nil
+	}
+	var misses []m.NearMiss
+	for i, target := range d.words {
+		if d.skip[i] || found[i] || stem == d.stems[i] {
+			continue
+		}
+		if reason := d.reason(word, target); reason != "" {
+			misses = append(misses, m.NearMiss{Target: i, Word: word, Reason: reason})
+		}
+	}
+	return misses
+}
+
+func (d *Detector) reason(word, target string) string {
+	switch {
+	case d.thesaurus.related(word, target):
+		return REASON_RELATED
+	case commonPrefix(word, target) >= MIN_PREFIX:
+		return REASON_PREFIX
+	case distance(word, target) <= maxDistance(word, target):
+		return REASON_SPELLING
+	}
+	return ""
+}
+
+func commonPrefix(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	n := 0
+	for n < len(ra) && n < len(rb) && ra[n] == rb[n] {
+		n++
+	}
+	return n
+}
+
+// short words allow one typo, long words two
+func maxDistance(a, b string) int {
+	shorter := min(len([]rune(a)), len([]rune(b)))
+	switch {
+	case shorter >= 8:
+		return 2
+	case shorter >= 4:
+		return 1
+	}
+	return 0
+}
+
+// distance is the edit distance between a and b, counting a swap of two
+// neighbouring letters as one edit since that is the most common typo.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,58 @@
+package nearmiss
+
+import (
+	"reflect"
+	"testing"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	challenge := m.Challenge{Words: []string{"the", "future", "belongs", "to", "believe", "dreams"}}
+	d := New(challenge, challenge.Words)
+	found := []bool{false, false, false, false, false, false}
+
+	tests := []struct {
+		word string
+		want []m.NearMiss
+	}{
+		{"hope", []m.NearMiss{{Target: 5, Word: "hope", Reason: REASON_RELATED}}},
+		{"belonging", []m.NearMiss{{Target: 2, Word: "belonging", Reason: REASON_PREFIX}}},
+		{"beleive", []m.NearMiss{{Target: 4, Word: "beleive", Reason: REASON_SPELLING}}},
+		// stopwords on either side are never close
+		{"then", nil},
+		{"them", nil},
+		{"banana", nil},
+		// the exact word is a match, not a near miss
+		{"future", nil},
+	}
+	for _, tt := range tests {
+		if got := d.Check(tt.word, tt.word, found); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+
+	found[5] = true
+	if got := d.Check("hope", "hope", found); got != nil {
+		t.Errorf("found words should not get near misses, got %v", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"light", "light", 0},
+		{"light", "lights", 1},
+		{"beleive", "believe", 1},
+		{"belive", "believe", 1},
+		{"sueño", "sueno", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package packs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/quality"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+const (
+	EASY   = "easy"
+	MEDIUM = "medium"
+	HARD   = "hard"
+)
+
+// Pack is a JSON file of challenges written by hand or generated, ready to
+// be checked and scheduled.
+type Pack struct {
+	Name string `json:"name"`
+	// default for challenges that don't name their own
+	Language string  `json:"language"`
+	Entries  []Entry `json:"challenges"`
+}
+
+type Entry struct {
+	Quote      string            `json:"quote"`
+	Author     string            `json:"author"`
+	Content    string            `json:"content"`
+	Language   string            `json:"language,omitempty"`
+	Difficulty string            `json:"difficulty,omitempty"`
+	Matching   m.MatchPolicy     `json:"matching,omitempty"`
+	Stopwords  m.StopwordPolicy  `json:"stopwords,omitempty"`
+	Tokenizer  tokenizer.Options `json:"tokenizer,omitempty"`
+}
+
+func Load(path string) (Pack, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Pack{}, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads a pack and rejects entries that can't be played at all.
+func Parse(r io.Reader) (Pack, error) {
+	var pack Pack
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&pack); err != nil {
+		return pack, err
+	}
+	for i, entry := range pack.Entries {
+		if strings.TrimSpace(entry.Quote) == "" || strings.TrimSpace(entry.Content) == "" {
+			return pack, fmt.Errorf("challenge %d: quote and content are required", i)
+		}
+		switch entry.Difficulty {
+		case "", EASY, MEDIUM, HARD:
+		default:
+			return pack, fmt.Errorf("challenge %d: unknown difficulty %q", i, entry.Difficulty)
+		}
+	}
+	return pack, nil
+}
+
+// Challenges turns the entries into challenges, with the quote split into
+// words.
+func (p Pack) Challenges() []m.Challenge {
+	challenges := make([]m.Challenge, len(p.Entries))
+	for i, entry := range p.Entries {
+		c := m.Challenge{
+			Quote:      entry.Quote,
+			Author:     entry.Author,
+			Content:    entry.Content,
+			Language:   entry.Language,
+			Difficulty: entry.Difficulty,
+			Matching:   entry.Matching,
+			Stopwords:  entry.Stopwords,
+			Tokenizer:  entry.Tokenizer,
+		}
+		if c.Language == "" {
+			c.Language = p.Language
+		}
+		c.Words = tokenizer.Words(c.Tokens())
+		challenges[i] = c
+	}
+	return challenges
+}
+
+// Check runs the starting content of every challenge through the
+// validator, ok is false when any of them failed.
+func (p Pack) Check(v quality.Validator) (reports []quality.Report, ok bool, err error) {
+	ok = true
+	for _, challenge := range p.Challenges() {
+		report, err := v.Validate(challenge)
+		if err != nil {
+			return reports, false, err
+		}
+		reports = append(reports, report)
+		if report.Status == quality.FAIL {
+			ok = false
+		}
+	}
+	return reports, ok, nil
+}
@@ -0,0 +1,33 @@
+package packs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	pack, err := Parse(strings.NewReader(`{"name": "spring", "language": "es", "challenges": [
+		{"quote": "La vida es sueño", "author": "Calderón", "content": "Un día largo.", "difficulty": "easy"},
+		{"quote": "Hope is the thing with feathers", "author": "Dickinson", "content": "A bird sings.", "language": "en"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenges := pack.Challenges()
+	if len(challenges) != 2 || challenges[0].Language != "es" || challenges[1].Language != "en" {
+		t.Fatalf("got %+v", challenges)
+	}
+	if strings.Join(challenges[0].Words, " ") != "la vida es sueño" || challenges[0].Difficulty != EASY {
+		t.Errorf("got %+v", challenges[0])
+	}
+
+	for _, bad := range []string{
+		`{"challenges": [{"quote": "x", "content": "y", "difficulty": "brutal"}]}`,
+		`{"challenges": [{"quote": "x", "content": " "}]}`,
+		`{"challenges": [{"quote": "x", "content": "y", "hint": "z"}]}`,
+	} {
+		if _, err := Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s parsed", bad)
+		}
+	}
+}
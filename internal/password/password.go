@@ -0,0 +1,57 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	// PBKDF2 rounds of new hashes, old hashes keep the count they were made with
+	ITERATIONS = 600000
+	SALT_SIZE  = 16
+)
+
+const SCHEME = "pbkdf2-sha256"
+
+var encoding = base64.RawStdEncoding
+
+// Hash makes a hash to keep in the config instead of a password, as
+// "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func Hash(password string) (string, error) {
+	salt := make([]byte, SALT_SIZE)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2.Key([]byte(password), salt, ITERATIONS, sha256.Size, sha256.New)
+	return fmt.Sprintf("%s$%d$%s$%s", SCHEME, ITERATIONS, encoding.EncodeToString(salt), encoding.EncodeToString(key)), nil
+}
+
+// Check tells whether password is the one hash was made from. Malformed
+// hashes match nothing.
+func Check(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != SCHEME {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := encoding.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got := pbkdf2.Key([]byte(password), salt, iterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,36 @@
+package password
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestCheckKnownHash(t *testing.T) {
+	// RFC 7914 section 11, PBKDF2-HMAC-SHA256 of "passwd" salted with "salt"
+	key, _ := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+	hash := SCHEME + "$1$" + encoding.EncodeToString([]byte("salt")) + "$" + encoding.EncodeToString(key)
+	if !Check(hash, "passwd") {
+		t.Errorf("%s didn't match", hash)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ITERATIONS = 1000
+	hash, err := Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Check(hash, "correct horse") {
+		t.Error("the password didn't match its hash")
+	}
+	for _, bad := range []struct{ hash, password string }{
+		{hash, "correct horse "},
+		{"correct horse", "correct horse"},
+		{"pbkdf2-sha256$x$c2FsdA$a2V5", "key"},
+		{"", ""},
+	} {
+		if Check(bad.hash, bad.password) {
+			t.Errorf("%q matched %q", bad.password, bad.hash)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package quality
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kirtansoni/words-weave/internal/associations"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/nearmiss"
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// share of the challenge words the starting content may already have
+	MAX_PREMATCHED  = 0.25
+	FAIL_PREMATCHED = 0.5
+	// distinct words, stopwords aside, the starting content needs to pick from
+	MIN_VOCABULARY  = 15
+	FAIL_VOCABULARY = 8
+	// share of the starting content that is stopwords
+	MAX_STOPWORDS  = 0.6
+	FAIL_STOPWORDS = 0.8
+	// challenge words this long are the rare ones, short words turn up in
+	// any passage
+	RARE_LENGTH = 6
+	// association weight that makes a word reachable, one attempt within
+	// the half-life of the graph is enough
+	MIN_STRENGTH = 0.5
+)
+
+const (
+	PASS = "pass"
+	WARN = "warn"
+	FAIL = "fail"
+)
+
+const (
+	CHECK_PREMATCHED = "prematched"
+	CHECK_VOCABULARY = "vocabulary"
+	CHECK_STOPWORDS  = "stopwords"
+	CHECK_REACHABLE  = "reachable"
+)
+
+type Check struct {
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	Value  float64 `json:"value"`
+	Detail string  `json:"detail"`
+	// the challenge words the check is about
+	Words []string `json:"words,omitempty"`
+}
+
+// Report is every check of a challenge's starting content, Status is the
+// worst of them.
+type Report struct {
+	Quote  string  `json:"quote"`
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Validator checks the starting content of challenges before they are
+// played. Without an association graph the thesaurus tells which words can
+// be reached.
+type Validator struct {
+	Stemmer matcher.Stemmer
+	Graph   *associations.Graph
+}
+
+func (v Validator) Validate(challenge m.Challenge) (Report, error) {
+	report := Report{Quote: challenge.Quote, Status: PASS}
+	given := challenge.Given()
+	found, err := v.prematched(challenge, given)
+	if err != nil {
+		return report, err
+	}
+	report.add(prematchedCheck(challenge, given, found))
+
+	set := stopwords.Get(challenge.GetLanguage())
+	words := tokenizer.Words(tokenizer.Tokenize(challenge.Content, challenge.TokenizerOptions()))
+	report.add(vocabularyCheck(words, set))
+	report.add(stopwordsCheck(words, set))
+
+	reachable, err := v.reachableCheck(challenge, found, set)
+	if err != nil {
+		return report, err
+	}
+	report.add(reachable)
+	return report, nil
+}
+
+func (r *Report) add(check Check) {
+	r.Checks = append(r.Checks, check)
+	if rank(check.Status) > rank(r.Status) {
+		r.Status = check.Status
+	}
+}
+
+func rank(status string) int {
+	return slices.Index([]string{PASS, WARN, FAIL}, status)
+}
+
+// grade is the status of a share that gets worse as it grows.
+func grade(value, warn, fail float64) string {
+	switch {
+	case value > fail:
+		return FAIL
+	case value > warn:
+		return WARN
+	}
+	return PASS
+}
+
+// prematched matches the starting content like a passage, the challenge
+// words it has are found before the first attempt.
+func (v Validator) prematched(challenge m.Challenge, given []bool) ([]bool, error) {
+	mt, err := matcher.New(v.Stemmer, challenge, given)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mt.Write(challenge.Content); err != nil {
+		return nil, err
+	}
+	if _, err := mt.Close(); err != nil {
+		return nil, err
+	}
+	return mt.Found(), nil
+}
+
+func prematchedCheck(challenge m.Challenge, given, found []bool) Check {
+	check := Check{Name: CHECK_PREMATCHED}
+	playable := 0
+	for i, word := range challenge.Words {
+		if given[i] {
+			continue
+		}
+		playable++
+		if found[i] {
+			check.Words = append(check.Words, word)
+		}
+	}
+	if playable > 0 {
+		check.Value = float64(len(check.Words)) / float64(playable)
+	}
+	check.Status = grade(check.Value, MAX_PREMATCHED, FAIL_PREMATCHED)
+	check.Detail = fmt.Sprintf("%d of %d challenge words are in the starting content", len(check.Words), playable)
+	return check
+}
+
+func vocabularyCheck(words []string, set stopwords.Set) Check {
+	var distinct []string
+	for _, word := range words {
+		if !set.Contains(word) && !slices.Contains(distinct, word) {
+			distinct = append(distinct, word)
+		}
+	}
+	check := Check{Name: CHECK_VOCABULARY, Value: float64(len(distinct))}
+	switch {
+	case len(distinct) < FAIL_VOCABULARY:
+		check.Status = FAIL
+	case len(distinct) < MIN_VOCABULARY:
+		check.Status = WARN
+	default:
+		check.Status = PASS
+	}
+	check.Detail = fmt.Sprintf("%d distinct words besides stopwords", len(distinct))
+	return check
+}
+
+func stopwordsCheck(words []string, set stopwords.Set) Check {
+	check := Check{Name: CHECK_STOPWORDS}
+	count := 0
+	for _, word := range words {
+		if set.Contains(word) {
+			count++
+		}
+	}
+	if len(words) > 0 {
+		check.Value = float64(count) / float64(len(words))
+	}
+	check.Status = grade(check.Value, MAX_STOPWORDS, FAIL_STOPWORDS)
+	check.Detail = fmt.Sprintf("%d of %d words are stopwords", count, len(words))
+	return check
+}
+
+// reachableCheck warns about rare challenge words nothing is known to lead
+// to. It never fails, the graph only knows what was played. The thesaurus is
+// a short list, words it doesn't have are left out rather than warned about.
+func (v Validator) reachableCheck(challenge m.Challenge, found []bool, set stopwords.Set) (Check, error) {
+	check := Check{Name: CHECK_REACHABLE, Status: PASS}
+	var rare []string
+	for i, word := range challenge.Words {
+		if !found[i] && !set.Contains(word) && len([]rune(word)) >= RARE_LENGTH && !slices.Contains(rare, word) {
+			rare = append(rare, word)
+		}
+	}
+	source := "the thesaurus"
+	var strength map[string]float64
+	if v.Graph != nil {
+		source = "the association graph"
+		var err error
+		if strength, err = v.Graph.Strength(challenge.GetLanguage(), rare); err != nil {
+			return check, err
+		}
+	}
+	checked, unknown := 0, 0
+	for _, word := range rare {
+		if v.Graph == nil {
+			// words of a group always have related words
+			if len(nearmiss.Related(challenge.GetLanguage(), word)) == 0 {
+				unknown++
+			} else {
+				checked++
+			}
+			continue
+		}
+		checked++
+		if strength[word] < MIN_STRENGTH {
+			check.Words = append(check.Words, word)
+		}
+	}
+	if checked > 0 {
+		check.Value = float64(checked-len(check.Words)) / float64(checked)
+	} else {
+		check.Value = 1
+	}
+	if len(check.Words) > 0 {
+		check.Status = WARN
+		check.Detail = fmt.Sprintf("nothing in %s leads to %s", source, strings.Join(check.Words, ", "))
+	} else {
+		check.Detail = fmt.Sprintf("%d rare words, all reachable by %s", checked, source)
+	}
+	if unknown > 0 {
+		check.Detail += fmt.Sprintf(", %d more it doesn't know", unknown)
+	}
+	return check, nil
+}
@@ -0,0 +1,82 @@
+package quality
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kirtansoni/words-weave/internal/associations"
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func check(report Report, name string) Check {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return Check{}
+}
+
+func TestValidate(t *testing.T) {
+	v := Validator{Stemmer: matcher.LocalStemmer{}}
+	challenge := m.Challenge{
+		Quote: "The future belongs to those who believe in the beauty of their dreams",
+		Content: "A quiet harbour wakes slowly. Fishermen mend nets, gulls circle above " +
+			"painted boats and the baker opens early for sailors heading out.",
+		Stopwords: m.StopwordsExclude,
+	}
+	challenge.Words = []string{"the", "future", "belongs", "to", "those", "who", "believe", "in", "the", "beauty", "of", "their", "dreams"}
+	report, err := v.Validate(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the thesaurus doesn't know belongs, it can't tell whether it is reachable
+	reachable := check(report, CHECK_REACHABLE)
+	if report.Status != PASS || reachable.Status != PASS || len(reachable.Words) != 0 {
+		t.Errorf("got %+v", report)
+	}
+
+	// the answer in the starting content, and hardly anything else
+	challenge.Content = "The future belongs to those who believe in dreams."
+	report, err = v.Validate(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != FAIL || check(report, CHECK_PREMATCHED).Status != FAIL || check(report, CHECK_VOCABULARY).Status != FAIL {
+		t.Errorf("got %+v", report)
+	}
+	if prematched := check(report, CHECK_PREMATCHED); len(prematched.Words) != 4 {
+		t.Errorf("prematched %v", prematched.Words)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	graph := associations.New(db)
+	challenge := m.Challenge{Words: []string{"serendipity", "future"}, Content: "Nothing here."}
+	if err := graph.Record(challenge, m.Entry{Input: "tomorrow", Matches: []m.WordMatch{{Target: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the thesaurus has future but not serendipity, which is left out
+	got, err := Validator{}.reachableCheck(challenge, []bool{false, false}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != PASS || len(got.Words) != 0 || got.Value != 1 || !strings.Contains(got.Detail, "1 more it doesn't know") {
+		t.Errorf("thesaurus: %+v", got)
+	}
+	got, err = Validator{Graph: graph}.reachableCheck(challenge, []bool{false, false}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != WARN || len(got.Words) != 1 || got.Value != 0.5 {
+		t.Errorf("graph: %+v", got)
+	}
+}
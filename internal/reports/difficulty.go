@@ -0,0 +1,203 @@
+package reports
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kirtansoni/words-weave/internal/analytics"
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+// WordReport is how a challenge word fared across the players of a
+// challenge.
+type WordReport struct {
+	Word string `json:"word"`
+	// share of players who found it, given words count as found by all
+	FoundRate float64 `json:"foundRate"`
+	// mean attempt it was found on, 0 when nobody found it
+	AverageAttempts float64 `json:"averageAttempts"`
+	Given           bool    `json:"given"`
+}
+
+// ChallengeReport sums up how hard a challenge was, from the attempts
+// logged for it. Practice replays are left out.
+type ChallengeReport struct {
+	Day       string  `json:"day"`
+	Language  string  `json:"language"`
+	Challenge int     `json:"challenge"`
+	Quote     string  `json:"quote"`
+	Players   int     `json:"players"`
+	Solved    int     `json:"solved"`
+	SolveRate float64 `json:"solveRate"`
+	// attempts a solve took, to how many players took that many
+	Attempts map[int]int `json:"attempts"`
+	// attempt players gave up after, for those who never solved it. Today's
+	// challenges count players still at it as abandoned so far.
+	Abandoned       map[int]int  `json:"abandoned"`
+	MedianAbandoned float64      `json:"medianAbandoned"`
+	Words           []WordReport `json:"words"`
+}
+
+// Hardest is the least found challenge word that isn't given.
+func (r ChallengeReport) Hardest() (WordReport, bool) {
+	var hardest WordReport
+	ok := false
+	for _, word := range r.Words {
+		if word.Given {
+			continue
+		}
+		if !ok || word.FoundRate < hardest.FoundRate {
+			hardest, ok = word, true
+		}
+	}
+	return hardest, ok
+}
+
+// Difficulty reports on every challenge scheduled from one day to another,
+// both included.
+func Difficulty(db *sql.DB, from, to string) ([]ChallengeReport, error) {
+	rows, err := db.Query(`SELECT DISTINCT play_date FROM quotes
+		WHERE play_date BETWEEN ? AND ? ORDER BY play_date`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reports := []ChallengeReport{}
+	for _, day := range days {
+		challenges, err := dataio.GetChallenges(db, day)
+		if err != nil {
+			return nil, err
+		}
+		for _, challenge := range challenges {
+			report, err := difficulty(db, challenge)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func difficulty(db *sql.DB, challenge m.Challenge) (ChallengeReport, error) {
+	report := ChallengeReport{
+		Day:       challenge.Date,
+		Language:  challenge.GetLanguage(),
+		Challenge: challenge.Ordinal,
+		Quote:     challenge.Quote,
+		Attempts:  make(map[int]int),
+		Abandoned: make(map[int]int),
+	}
+	players, err := analytics.New(db).Plays(challenge.Date, report.Language, challenge.Ordinal)
+	if err != nil {
+		return report, err
+	}
+
+	given := challenge.Given()
+	missing := 0
+	for i := range given {
+		if !given[i] {
+			missing++
+		}
+	}
+	found := make([]int, len(given))
+	sums := make([]int, len(given))
+	var abandoned []int
+	for _, p := range players {
+		solvedAt, n := 0, 0
+		for target, attempt := range p.Found {
+			if target < len(given) && !given[target] {
+				found[target]++
+				sums[target] += attempt
+				solvedAt = max(solvedAt, attempt)
+				n++
+			}
+		}
+		if n == missing {
+			report.Solved++
+			report.Attempts[solvedAt]++
+		} else {
+			report.Abandoned[p.Last]++
+			abandoned = append(abandoned, p.Last)
+		}
+	}
+	report.Players = len(players)
+	if report.Players > 0 {
+		report.SolveRate = float64(report.Solved) / float64(report.Players)
+	}
+	report.MedianAbandoned = analytics.Median(abandoned)
+
+	for i, word := range challenge.Words {
+		w := WordReport{Word: word, Given: given[i]}
+		switch {
+		case given[i]:
+			w.FoundRate = 1
+		case report.Players > 0:
+			w.FoundRate = float64(found[i]) / float64(report.Players)
+		}
+		if found[i] > 0 {
+			w.AverageAttempts = float64(sums[i]) / float64(found[i])
+		}
+		report.Words = append(report.Words, w)
+	}
+	return report, nil
+}
+
+// histogram writes "3:2;4:1" for three attempts twice and four once.
+func histogram(h map[int]int) string {
+	keys := make([]int, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%d:%d", k, h[k])
+	}
+	return strings.Join(parts, ";")
+}
+
+// WriteCSV writes one row per challenge, word details are packed into the
+// last column as word:found rate:average attempts.
+func WriteCSV(w io.Writer, reports []ChallengeReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"day", "language", "challenge", "quote", "players", "solved", "solve_rate",
+		"attempts", "abandoned", "median_abandoned", "hardest_word", "hardest_found_rate", "words"})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, r := range reports {
+		hardest, ok := r.Hardest()
+		hardestRate := ""
+		if ok {
+			hardestRate = f(hardest.FoundRate)
+		}
+		words := make([]string, len(r.Words))
+		for i, word := range r.Words {
+			words[i] = fmt.Sprintf("%s:%s:%s", word.Word, f(word.FoundRate), f(word.AverageAttempts))
+		}
+		cw.Write([]string{r.Day, r.Language, strconv.Itoa(r.Challenge), r.Quote,
+			strconv.Itoa(r.Players), strconv.Itoa(r.Solved), f(r.SolveRate),
+			histogram(r.Attempts), histogram(r.Abandoned), f(r.MedianAbandoned),
+			hardest.Word, hardestRate, strings.Join(words, ";")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
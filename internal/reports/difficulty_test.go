@@ -0,0 +1,82 @@
+package reports
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kirtansoni/words-weave/internal/analytics"
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestDifficulty(t *testing.T) {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	challenge := m.Challenge{Quote: "Dreams need the brave", Words: []string{"dreams", "need", "the", "brave"}, Stopwords: m.StopwordsExclude}
+	if err := dataio.SaveChallenges(db, "2026-03-04", []m.Challenge{challenge}); err != nil {
+		t.Fatal(err)
+	}
+
+	log := analytics.New(db)
+	for _, e := range []analytics.Event{
+		// solved on the third attempt
+		{Session: "a", Attempt: 1, Matched: []int{0}},
+		{Session: "a", Attempt: 2},
+		{Session: "a", Attempt: 3, Matched: []int{1, 3}},
+		// solved on the second
+		{Session: "b", Attempt: 1, Matched: []int{1}},
+		{Session: "b", Attempt: 2, Matched: []int{0, 3}},
+		// gave up after four, never finding "brave"
+		{Session: "c", Attempt: 1, Matched: []int{0}},
+		{Session: "c", Attempt: 4, Matched: []int{1}},
+		{Session: "c", Attempt: 5, ErrorClass: analytics.ERROR_LLM},
+		// "brave" revealed by a hint after two attempts
+		{Session: "d", Attempt: 1, Matched: []int{0, 1}},
+		{Session: "d", Attempt: 2},
+		{Session: "d", Attempt: 5, Matched: []int{3}, Hint: "reveal"},
+	} {
+		e.Day, e.Language = "2026-03-04", "en"
+		if err := log.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := Difficulty(db, "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("reports %+v", reports)
+	}
+	r := reports[0]
+	if r.Players != 4 || r.Solved != 3 || !reflect.DeepEqual(r.Attempts, map[int]int{2: 1, 3: 1, 5: 1}) ||
+		!reflect.DeepEqual(r.Abandoned, map[int]int{4: 1}) || r.MedianAbandoned != 4 {
+		t.Errorf("report %+v", r)
+	}
+	want := []WordReport{
+		{Word: "dreams", FoundRate: 1, AverageAttempts: 5.0 / 4},
+		{Word: "need", FoundRate: 1, AverageAttempts: 9.0 / 4},
+		{Word: "the", FoundRate: 1, Given: true},
+		{Word: "brave", FoundRate: 3.0 / 4, AverageAttempts: 10.0 / 3},
+	}
+	if !reflect.DeepEqual(r.Words, want) {
+		t.Errorf("words %+v", r.Words)
+	}
+	if hardest, _ := r.Hardest(); hardest.Word != "brave" {
+		t.Errorf("hardest %q", hardest.Word)
+	}
+
+	var b bytes.Buffer
+	if err := WriteCSV(&b, reports); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "2026-03-04,en,0,Dreams need the brave,4,3,0.75,2:1;3:1;5:1,4:1,4.00,brave,0.75,") {
+		t.Errorf("csv\n%s", b.String())
+	}
+}
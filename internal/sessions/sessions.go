@@ -3,11 +3,17 @@ package sessions
 import (
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	m "github.com/kirtansoni/words-weave/internal/models"
 )
 
+var (
+	// the player cookie outlives sessions so stats carry across days
+	PLAYER_COOKIE_AGE = 365 * 24 * time.Hour
+)
+
 type SessionManager struct {
 	sessions map[string]*m.State
 	sync.RWMutex
@@ -52,15 +58,36 @@ func (s *SessionManager) SetSessionID(w http.ResponseWriter) string {
 
 func (s *SessionManager) GetSessionID(r *http.Request) (string, error) {
 	cookie, err := r.Cookie("session")
-	sessionID := cookie.Value
-	if err != nil || sessionID == "" {
+	if err != nil || cookie.Value == "" {
 		return "", err
 	}
-	return sessionID, nil
+	return cookie.Value, nil
+}
+
+// GetPlayerID returns the player identity of the browser, a new one is
+// set when there is none. The cookie is refreshed so active players keep it.
+func (s *SessionManager) GetPlayerID(w http.ResponseWriter, r *http.Request) string {
+	playerID := ""
+	if cookie, err := r.Cookie("player"); err == nil {
+		playerID = cookie.Value
+	}
+	if playerID == "" {
+		playerID = uuid.NewString()
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     "player",
+		Value:    playerID,
+		SameSite: http.SameSiteLaxMode,
+		HttpOnly: true,
+		Secure:   true,
+		Path:     "/",
+		MaxAge:   int(PLAYER_COOKIE_AGE.Seconds()),
+	})
+	return playerID
 }
 
-// Not used yet
-func (s *SessionManager) getActivePlayers() int {
+// ActivePlayers counts the sessions played in the last few minutes.
+func (s *SessionManager) ActivePlayers() int {
 	s.RLock()
 	defer s.RUnlock()
 	counter := 0
@@ -72,6 +99,26 @@ func (s *SessionManager) getActivePlayers() int {
 	return counter
 }
 
+// Sessions is a copy of the session map, the states are shared.
+func (s *SessionManager) Sessions() map[string]*m.State {
+	s.RLock()
+	defer s.RUnlock()
+	sessions := make(map[string]*m.State, len(s.sessions))
+	for key, state := range s.sessions {
+		sessions[key] = state
+	}
+	return sessions
+}
+
+// DeleteState drops a session's state, the next request starts it over.
+func (s *SessionManager) DeleteState(SessionID string) bool {
+	s.Lock()
+	defer s.Unlock()
+	_, exists := s.sessions[SessionID]
+	delete(s.sessions, SessionID)
+	return exists
+}
+
 // Todo
 func (s *SessionManager) SaveAllSessionsToDB() {
 	panic("unimplemented")
@@ -0,0 +1,137 @@
+package share
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+var (
+	TITLE = "Words Weave"
+	// day 1 of the game, share strings count days from here
+	LAUNCH = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	// squares for attempts that found 0, 1, 2 and 3 or more words
+	SQUARES = []string{"⬛", "🟨", "🟧", "🟩"}
+	HINT    = "💡"
+)
+
+// Day numbers the daily challenge set being played at t.
+func Day(t time.Time) int {
+	return int(t.UTC().Sub(LAUNCH).Hours()/24) + 1
+}
+
+// Summary is a spoiler-free result of a player's day.
+type Summary struct {
+	ID       string `json:"id"`
+	Day      int    `json:"day"`
+	Language string `json:"language"`
+	// an archived day replayed, not the daily game
+	Practice   bool       `json:"practice"`
+	Challenges []m.Result `json:"challenges"`
+	Score      int        `json:"score"`
+	// days in a row the player finished, 0 when unknown
+	Streak int    `json:"streak"`
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+}
+
+// ID is short and stable for a session's day, so sharing again updates the
+// same permalink. It is a hash and doesn't give the session away.
+func ID(sessionID string, day int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", sessionID, day)))
+	return base64.RawURLEncoding.EncodeToString(sum[:6])
+}
+
+func New(id string, day int, language string, results []m.Result) Summary {
+	summary := Summary{ID: id, Day: day, Language: language, Challenges: results}
+	for _, result := range results {
+		summary.Score += result.Score
+	}
+	return summary
+}
+
+// Version changes whenever the summary does, it keeps cached cards fresh.
+func Version(summary Summary) string {
+	b, _ := json.Marshal(summary)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:6])
+}
+
+// Grid is one square per attempt, showing how many words it unlocked.
+func Grid(result m.Result) string {
+	var b strings.Builder
+	for _, n := range result.Unlocked {
+		b.WriteString(SQUARES[min(n, len(SQUARES)-1)])
+	}
+	b.WriteString(strings.Repeat(HINT, result.Hints))
+	return b.String()
+}
+
+// Text is the share string, url is appended when not empty.
+func Text(summary Summary, url string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s #%d", TITLE, summary.Day)
+	if summary.Language != m.DEFAULT_LANGUAGE {
+		fmt.Fprintf(&b, " (%s)", summary.Language)
+	}
+	if summary.Practice {
+		b.WriteString(" practice")
+	}
+	fmt.Fprintf(&b, " · %d pts\n", summary.Score)
+	for _, result := range summary.Challenges {
+		used := fmt.Sprint(result.Attempts)
+		if !result.Solved {
+			used = "X"
+		}
+		limit := "∞"
+		if result.MaxAttempts > 0 {
+			limit = fmt.Sprint(result.MaxAttempts)
+		}
+		fmt.Fprintf(&b, "%d. %s/%s", result.Challenge+1, used, limit)
+		if result.Mode != "" && result.Mode != "classic" {
+			fmt.Fprintf(&b, " %s", result.Mode)
+		}
+		fmt.Fprintf(&b, " %s\n", Grid(result))
+	}
+	if url != "" {
+		b.WriteString(url + "\n")
+	}
+	return b.String()
+}
+
+// Store keeps shared summaries for their permalinks.
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (st *Store) Save(summary Summary) error {
+	b, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	_, err = st.db.Exec(`INSERT INTO shared_results (id, day, summary, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET summary = excluded.summary, updated_at = excluded.updated_at`,
+		summary.ID, summary.Day, string(b), time.Now())
+	return err
+}
+
+// Get returns sql.ErrNoRows for unknown ids.
+func (st *Store) Get(id string) (Summary, error) {
+	var summary Summary
+	var b string
+	if err := st.db.QueryRow(`SELECT summary FROM shared_results WHERE id = ?`, id).Scan(&b); err != nil {
+		return summary, err
+	}
+	err := json.Unmarshal([]byte(b), &summary)
+	return summary, err
+}
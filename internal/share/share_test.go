@@ -0,0 +1,56 @@
+package share
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestText(t *testing.T) {
+	summary := New("abc", Day(time.Date(2025, time.January, 10, 15, 0, 0, 0, time.UTC)), "en", []m.Result{
+		{Challenge: 0, Mode: "classic", Attempts: 4, MaxAttempts: 25, Unlocked: []int{2, 0, 1, 5}, Solved: true, Score: 100},
+		{Challenge: 1, Mode: "zen", Attempts: 2, Unlocked: []int{1, 0}, Hints: 1},
+	})
+	want := "Words Weave #10 · 100 pts\n" +
+		"1. 4/25 🟧⬛🟨🟩\n" +
+		"2. X/∞ zen 🟨⬛💡\n" +
+		"http://localhost/r/abc\n"
+	if text := Text(summary, "http://localhost/r/abc"); text != want {
+		t.Errorf("got\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	st := NewStore(db)
+
+	id := ID("session", 3)
+	if id != ID("session", 3) || id == ID("session", 4) || len(id) != 8 {
+		t.Fatalf("id %q should be short and stable per day", id)
+	}
+	first := New(id, 3, "en", []m.Result{{Attempts: 3, Unlocked: []int{1, 1, 1}, Solved: true, Score: 100}})
+	second := New(id, 3, "en", append(first.Challenges, m.Result{Challenge: 1, Attempts: 1, Unlocked: []int{0}}))
+	for _, summary := range []Summary{first, second} {
+		if err := st.Save(summary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := st.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, second) {
+		t.Errorf("got %+v, want %+v", got, second)
+	}
+	if _, err := st.Get("missing"); err != sql.ErrNoRows {
+		t.Errorf("missing id: %v", err)
+	}
+}
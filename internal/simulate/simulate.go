@@ -0,0 +1,339 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+
+	g "github.com/kirtansoni/words-weave/internal/game"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/nearmiss"
+	"github.com/kirtansoni/words-weave/internal/stopwords"
+	"github.com/kirtansoni/words-weave/internal/tokenizer"
+)
+
+var (
+	// words the bot picks for an attempt
+	PICKS = 3
+	// modes without an attempt limit give up after this many
+	MAX_STEPS = 50
+)
+
+const (
+	GREEDY = "greedy"
+	BEAM   = "beam"
+)
+
+// how promising a passage word is for a missing challenge word
+var SCORES = map[string]int{
+	"exact":                  5,
+	nearmiss.REASON_RELATED:  3,
+	nearmiss.REASON_PREFIX:   2,
+	nearmiss.REASON_SPELLING: 1,
+}
+
+type Config struct {
+	Runs int
+	// GREEDY picks from the latest passage, BEAM goes back to the most
+	// promising of the best Beam passages so far
+	Policy  string
+	Beam    int
+	Mode    string
+	Seed    uint64
+	LLM     l.Provider
+	Stemmer matcher.Stemmer
+}
+
+// Report is how a bot did on one challenge over all runs.
+type Report struct {
+	Quote      string  `json:"quote"`
+	Difficulty string  `json:"difficulty,omitempty"`
+	Runs       int     `json:"runs"`
+	Solved     int     `json:"solved"`
+	SolveRate  float64 `json:"solveRate"`
+	// mean attempts of the solved runs
+	ExpectedAttempts float64 `json:"expectedAttempts"`
+	// solved runs by the attempts they took
+	Attempts map[int]int `json:"attempts"`
+}
+
+// Run plays a challenge cfg.Runs times through the game handlers, each run
+// is a new player.
+func Run(challenge m.Challenge, cfg Config) (Report, error) {
+	if cfg.Policy == "" {
+		cfg.Policy = GREEDY
+	}
+	if cfg.Policy != GREEDY && cfg.Policy != BEAM {
+		return Report{}, fmt.Errorf("unknown policy %q", cfg.Policy)
+	}
+	if cfg.Beam < 1 {
+		cfg.Beam = 1
+	}
+	game := g.GetGame()
+	// the bot doesn't wait between attempts like a player would
+	game.AttemptInterval = 0
+	game.LLM = cfg.LLM
+	game.Stemmer = cfg.Stemmer
+	game.SetChallenges([]m.Challenge{challenge})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /game", game.Getgamestate)
+	mux.HandleFunc("POST /game", game.Postgamestate)
+	mux.HandleFunc("POST /game/rewind", game.Postrewind)
+
+	report := Report{
+		Quote:      challenge.Quote,
+		Difficulty: challenge.Difficulty,
+		Runs:       cfg.Runs,
+		Attempts:   make(map[int]int),
+	}
+	total := 0
+	for run := range cfg.Runs {
+		b := &bot{
+			mux:       mux,
+			config:    cfg,
+			challenge: challenge,
+			rng:       rand.New(rand.NewPCG(cfg.Seed, uint64(run))),
+			stopwords: stopwords.Get(challenge.GetLanguage()),
+			tried:     make(map[int][]string),
+		}
+		solved, attempts, err := b.play()
+		if err != nil {
+			return report, err
+		}
+		if solved {
+			report.Solved++
+			report.Attempts[attempts]++
+			total += attempts
+		}
+	}
+	if report.Runs > 0 {
+		report.SolveRate = float64(report.Solved) / float64(report.Runs)
+	}
+	if report.Solved > 0 {
+		report.ExpectedAttempts = float64(total) / float64(report.Solved)
+	}
+	return report, nil
+}
+
+type bot struct {
+	mux       *http.ServeMux
+	config    Config
+	challenge m.Challenge
+	rng       *rand.Rand
+	stopwords stopwords.Set
+	cookies   []*http.Cookie
+	// words already picked from each entry, -1 is the starting content
+	tried map[int][]string
+	// entries the beam policy may go back to
+	frontier []int
+}
+
+type candidate struct {
+	word  string
+	score int
+}
+
+func (b *bot) play() (bool, int, error) {
+	state, err := b.get()
+	if err != nil {
+		return false, 0, err
+	}
+	b.frontier = []int{-1}
+	for steps := 0; steps < MAX_STEPS; steps++ {
+		if done(state) {
+			break
+		}
+		entry := state.Head
+		if b.config.Policy == BEAM {
+			entry = b.pick(state)
+			if entry != state.Head {
+				if state, err = b.rewind(entry); err != nil {
+					return false, 0, err
+				}
+			}
+		}
+		words := b.choose(state, entry)
+		if len(words) == 0 {
+			// nothing left to pick from this passage
+			b.frontier = slices.DeleteFunc(b.frontier, func(id int) bool { return id == entry })
+			if b.config.Policy == GREEDY || len(b.frontier) == 0 {
+				break
+			}
+			continue
+		}
+		b.tried[entry] = append(b.tried[entry], words...)
+		code, err := b.post(strings.Join(words, " "))
+		if err != nil {
+			return false, 0, err
+		}
+		if code == http.StatusExpectationFailed {
+			break
+		}
+		if state, err = b.get(); err != nil {
+			return false, 0, err
+		}
+		if code == http.StatusOK && state.Head >= 0 {
+			b.frontier = append(b.frontier, state.Head)
+		}
+	}
+	return solved(state), state.Attempts, nil
+}
+
+func done(state m.ResponseStruct) bool {
+	return solved(state) || state.AttemptsLeft == 0
+}
+
+func solved(state m.ResponseStruct) bool {
+	return !slices.Contains(state.Progress, false)
+}
+
+func (b *bot) passage(state m.ResponseStruct, entry int) string {
+	if entry < 0 {
+		return b.challenge.Content
+	}
+	return state.Tree[entry].Content
+}
+
+// candidates scores the untried words of a passage, best first.
+func (b *bot) candidates(state m.ResponseStruct, passage string, tried []string) []candidate {
+	detector := nearmiss.New(b.challenge, b.challenge.Words)
+	mode := g.GetMode(b.config.Mode)
+	var words []string
+	for _, word := range tokenizer.Words(tokenizer.Tokenize(passage, b.challenge.TokenizerOptions())) {
+		// stopwords are noise unless the quote needs them
+		stopword := b.stopwords.Contains(word) && !slices.Contains(b.challenge.Words, word)
+		if stopword || slices.Contains(tried, word) || slices.Contains(words, word) {
+			continue
+		}
+		if mode.NoTargetInput && slices.Contains(b.challenge.Words, word) {
+			continue
+		}
+		words = append(words, word)
+	}
+	// random order first so ties break differently every run
+	b.rng.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	candidates := make([]candidate, len(words))
+	for i, word := range words {
+		candidates[i].word = word
+		for t, target := range b.challenge.Words {
+			if !state.Progress[t] && word == target {
+				candidates[i].score += SCORES["exact"]
+			}
+		}
+		for _, miss := range detector.Check(word, word, state.Progress) {
+			candidates[i].score += SCORES[miss.Reason]
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return b.score - a.score })
+	return candidates
+}
+
+// choose picks the best words of an entry's passage, when none of them
+// leads anywhere the shuffle makes it a random pick.
+func (b *bot) choose(state m.ResponseStruct, entry int) []string {
+	candidates := b.candidates(state, b.passage(state, entry), b.tried[entry])
+	var words []string
+	for _, c := range candidates[:min(PICKS, len(candidates))] {
+		words = append(words, c.word)
+	}
+	return words
+}
+
+// pick keeps the Beam most promising entries and returns the best one.
+func (b *bot) pick(state m.ResponseStruct) int {
+	value := func(entry int) int {
+		total := 0
+		candidates := b.candidates(state, b.passage(state, entry), b.tried[entry])
+		for _, c := range candidates[:min(PICKS, len(candidates))] {
+			total += c.score
+		}
+		return total
+	}
+	values := make(map[int]int, len(b.frontier))
+	for _, entry := range b.frontier {
+		values[entry] = value(entry)
+	}
+	// newer entries first on ties, they carry more of the progress
+	slices.SortStableFunc(b.frontier, func(x, y int) int {
+		if values[x] != values[y] {
+			return values[y] - values[x]
+		}
+		return y - x
+	})
+	if len(b.frontier) > b.config.Beam {
+		b.frontier = b.frontier[:b.config.Beam]
+	}
+	return b.frontier[0]
+}
+
+func (b *bot) do(method, url string, body any) (*httptest.ResponseRecorder, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	query := "?mode=" + b.config.Mode + "&lang=" + b.challenge.GetLanguage()
+	r := httptest.NewRequest(method, url+query, reader)
+	for _, c := range b.cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	b.mux.ServeHTTP(w, r)
+	for _, c := range w.Result().Cookies() {
+		b.cookies = slices.DeleteFunc(b.cookies, func(old *http.Cookie) bool { return old.Name == c.Name })
+		b.cookies = append(b.cookies, c)
+	}
+	return w, nil
+}
+
+func (b *bot) get() (m.ResponseStruct, error) {
+	var state m.ResponseStruct
+	w, err := b.do("GET", "/game", nil)
+	if err != nil {
+		return state, err
+	}
+	if w.Code != http.StatusOK {
+		return state, fmt.Errorf("GET /game: %d %s", w.Code, strings.TrimSpace(w.Body.String()))
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &state)
+	return state, err
+}
+
+// post makes an attempt, rejected input only costs the bot a step.
+func (b *bot) post(input string) (int, error) {
+	w, err := b.do("POST", "/game", map[string]string{"input": input})
+	if err != nil {
+		return 0, err
+	}
+	switch w.Code {
+	case http.StatusOK, http.StatusBadRequest, http.StatusExpectationFailed:
+		return w.Code, nil
+	}
+	return w.Code, fmt.Errorf("POST /game: %d %s", w.Code, strings.TrimSpace(w.Body.String()))
+}
+
+func (b *bot) rewind(entry int) (m.ResponseStruct, error) {
+	var state m.ResponseStruct
+	w, err := b.do("POST", "/game/rewind", map[string]int{"entry": entry})
+	if err != nil {
+		return state, err
+	}
+	if w.Code != http.StatusOK {
+		return state, errors.New("POST /game/rewind: " + strings.TrimSpace(w.Body.String()))
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &state)
+	return state, err
+}
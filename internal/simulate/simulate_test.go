@@ -0,0 +1,45 @@
+package simulate
+
+import (
+	"reflect"
+	"testing"
+
+	g "github.com/kirtansoni/words-weave/internal/game"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func TestRun(t *testing.T) {
+	challenge := m.GetChallenges()[0]
+	for _, policy := range []string{GREEDY, BEAM} {
+		cfg := Config{Runs: 5, Policy: policy, Beam: 3, Seed: 7, LLM: l.Fake{Seed: 7}, Stemmer: matcher.LocalStemmer{}}
+		report, err := Run(challenge, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", policy, err)
+		}
+		if report.Runs != 5 || report.Solved > 5 {
+			t.Errorf("%s: %+v", policy, report)
+		}
+		solved := 0
+		for attempts, n := range report.Attempts {
+			if attempts < 1 || attempts > g.MAX_ATTEMPTS {
+				t.Errorf("%s: solved in %d attempts", policy, attempts)
+			}
+			solved += n
+		}
+		if solved != report.Solved {
+			t.Errorf("%s: histogram counts %d of %d solved runs", policy, solved, report.Solved)
+		}
+		again, err := Run(challenge, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report, again) {
+			t.Errorf("%s: same seed gave %+v and %+v", policy, report, again)
+		}
+	}
+	if g.GetGame().AttemptInterval != g.LLM_TIMOUT || g.LLM_TIMOUT == 0 {
+		t.Error("the simulation changed the spacing of other games")
+	}
+}
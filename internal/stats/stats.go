@@ -0,0 +1,180 @@
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+var (
+	// days a player may miss without losing their streak
+	STREAK_GRACE = 1
+	DAY_FORMAT   = "2006-01-02"
+)
+
+// Stats are a player's lifetime numbers.
+type Stats struct {
+	Played        int     `json:"played"`
+	Won           int     `json:"won"`
+	WinRate       float64 `json:"winRate"`
+	CurrentStreak int     `json:"currentStreak"`
+	MaxStreak     int     `json:"maxStreak"`
+	// attempts a won challenge took, to how many challenges took that many
+	Histogram       map[int]int `json:"histogram"`
+	AverageAttempts float64     `json:"averageAttempts"`
+	// the player's last day with a win, in their timezone
+	LastDay string `json:"lastDay,omitempty"`
+}
+
+// Store keeps every finished challenge of a player and the stats derived
+// from them.
+type Store struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Location is the player's timezone, UTC when it is unknown.
+func Location(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil && name != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// daysBetween counts calendar days from a to b, both YYYY-MM-DD.
+func daysBetween(a, b string) int {
+	ta, err := time.Parse(DAY_FORMAT, a)
+	if err != nil {
+		return 0
+	}
+	tb, err := time.Parse(DAY_FORMAT, b)
+	if err != nil {
+		return 0
+	}
+	return int(tb.Sub(ta).Hours() / 24)
+}
+
+// alive tells whether a streak last extended on lastDay still stands on
+// today, up to STREAK_GRACE days may be missed in between.
+func alive(lastDay, today string) bool {
+	return lastDay != "" && daysBetween(lastDay, today) <= 1+STREAK_GRACE
+}
+
+// Record adds a finished challenge of the set of day to the player's stats.
+// A challenge is only counted once, recording it again does nothing.
+// Practice on an archived day counts like any other game but leaves the
+// streak alone.
+func (st *Store) Record(player string, loc *time.Location, language string, day string, result m.Result, finished time.Time) error {
+	// results are kept by the day of the set, a player can finish two sets
+	// on one day of their own. Streaks go by the player's days.
+	local := finished.In(loc).Format(DAY_FORMAT)
+	if result.Practice != "" {
+		day = result.Practice
+	}
+	if day == "" {
+		day = local
+	}
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT OR IGNORE INTO player_results
+		(player, day, practice, language, challenge, mode, attempts, solved, score, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		player, day, result.Practice != "", language, result.Challenge, result.Mode, result.Attempts, result.Solved, result.Score, finished)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	stats, total, err := get(tx, player)
+	if err != nil {
+		return err
+	}
+	stats.Played++
+	total += result.Attempts
+	if result.Solved {
+		stats.Won++
+		stats.Histogram[result.Attempts]++
+	}
+	if result.Solved && result.Practice == "" {
+		// a day extends the streak once, however many challenges are won
+		switch {
+		case stats.LastDay == local:
+		case alive(stats.LastDay, local):
+			stats.CurrentStreak++
+			stats.LastDay = local
+		default:
+			stats.CurrentStreak = 1
+			stats.LastDay = local
+		}
+		stats.MaxStreak = max(stats.MaxStreak, stats.CurrentStreak)
+	}
+
+	histogram, err := json.Marshal(stats.Histogram)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO player_stats
+		(player, played, won, total_attempts, current_streak, max_streak, last_day, histogram, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (player) DO UPDATE SET played = excluded.played, won = excluded.won,
+			total_attempts = excluded.total_attempts, current_streak = excluded.current_streak,
+			max_streak = excluded.max_streak, last_day = excluded.last_day,
+			histogram = excluded.histogram, updated_at = excluded.updated_at`,
+		player, stats.Played, stats.Won, total, stats.CurrentStreak, stats.MaxStreak, stats.LastDay, string(histogram), time.Now())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns the player's stats as of now, in their timezone. Players
+// without any finished challenge get zeroes.
+func (st *Store) Get(player string, loc *time.Location, now time.Time) (Stats, error) {
+	stats, _, err := get(st.db, player)
+	if err != nil {
+		return stats, err
+	}
+	if !alive(stats.LastDay, now.In(loc).Format(DAY_FORMAT)) {
+		stats.CurrentStreak = 0
+	}
+	return stats, nil
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// get also returns the attempts of every challenge added up.
+func get(q queryer, player string) (Stats, int, error) {
+	stats := Stats{Histogram: make(map[int]int)}
+	var total int
+	var histogram string
+	err := q.QueryRow(`SELECT played, won, total_attempts, current_streak, max_streak, last_day, histogram
+		FROM player_stats WHERE player = ?`, player).
+		Scan(&stats.Played, &stats.Won, &total, &stats.CurrentStreak, &stats.MaxStreak, &stats.LastDay, &histogram)
+	if err == sql.ErrNoRows {
+		return stats, 0, nil
+	}
+	if err != nil {
+		return stats, 0, err
+	}
+	if err := json.Unmarshal([]byte(histogram), &stats.Histogram); err != nil {
+		return stats, 0, err
+	}
+	if stats.Played > 0 {
+		stats.WinRate = float64(stats.Won) / float64(stats.Played)
+		stats.AverageAttempts = float64(total) / float64(stats.Played)
+	}
+	return stats, total, nil
+}
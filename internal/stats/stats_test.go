@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	dataio "github.com/kirtansoni/words-weave/internal/database"
+	m "github.com/kirtansoni/words-weave/internal/models"
+)
+
+func testStore(t *testing.T) *Store {
+	db, err := dataio.Open(":memory:", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := New(db)
+	return st
+}
+
+func TestStreaks(t *testing.T) {
+	st := testStore(t)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	won := func(challenge, attempts int) m.Result {
+		return m.Result{Challenge: challenge, Attempts: attempts, Solved: true}
+	}
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, time.March, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	steps := []struct {
+		result   m.Result
+		finished time.Time
+	}{
+		// 20:00 UTC is already the 2nd in Tokyo
+		{won(0, 3), day(1, 20)},
+		// recorded twice, counted once
+		{won(0, 3), day(1, 20)},
+		// same Tokyo day, the streak doesn't grow
+		{won(1, 5), day(2, 2)},
+		{m.Result{Challenge: 0, Attempts: 25}, day(3, 2)},
+		// the 3rd was lost, a missed day the grace allows
+		{won(0, 3), day(4, 2)},
+		// practice counts but doesn't keep the streak going
+		{m.Result{Challenge: 0, Attempts: 4, Solved: true, Practice: "2026-03-01"}, day(7, 2)},
+		// three days off break it
+		{won(0, 4), day(9, 2)},
+	}
+	for _, step := range steps {
+		// the sets roll over at midnight UTC
+		set := step.finished.UTC().Format(DAY_FORMAT)
+		if err := st.Record("p1", tokyo, "en", set, step.result, step.finished); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := st.Get("p1", tokyo, day(9, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Stats{
+		Played:          6,
+		Won:             5,
+		WinRate:         5.0 / 6,
+		CurrentStreak:   1,
+		MaxStreak:       2,
+		Histogram:       map[int]int{3: 2, 4: 2, 5: 1},
+		AverageAttempts: 44.0 / 6,
+		LastDay:         "2026-03-09",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got, _ := st.Get("p1", tokyo, day(12, 3)); got.CurrentStreak != 0 {
+		t.Errorf("streak should lapse after the grace, got %d", got.CurrentStreak)
+	}
+	if got, _ := st.Get("nobody", time.UTC, day(1, 0)); got.Played != 0 || got.Histogram == nil {
+		t.Errorf("unknown player: %+v", got)
+	}
+}
+
+func TestTwoSetsOnOneLocalDay(t *testing.T) {
+	st := testStore(t)
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	won := m.Result{Challenge: 0, Attempts: 3, Solved: true}
+	// 16:30 and 17:30 on the 1st in Los Angeles, either side of the rollover
+	if err := st.Record("p1", la, "en", "2026-07-01", won, time.Date(2026, 7, 1, 23, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Record("p1", la, "en", "2026-07-02", won, time.Date(2026, 7, 2, 0, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.Get("p1", la, time.Date(2026, 7, 2, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Played != 2 || got.Won != 2 || got.Histogram[3] != 2 || got.CurrentStreak != 1 || got.LastDay != "2026-07-01" {
+		t.Errorf("got %+v", got)
+	}
+}
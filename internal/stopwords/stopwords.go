@@ -0,0 +1,50 @@
+package stopwords
+
+import (
+	"bufio"
+	"embed"
+	"strings"
+	"sync"
+)
+
+var (
+	DEFAULT_LANGUAGE = "en"
+	//go:embed lists
+	lists embed.FS
+	cache sync.Map
+)
+
+// Set is the stopword list of one language.
+type Set map[string]bool
+
+// Get loads the embedded list for language, "" being DEFAULT_LANGUAGE. A
+// language without a list has no stopwords.
+func Get(language string) Set {
+	if language == "" {
+		language = DEFAULT_LANGUAGE
+	}
+	if set, ok := cache.Load(language); ok {
+		return set.(Set)
+	}
+	file, err := lists.Open("lists/" + language + ".txt")
+	if err != nil {
+		return Set{}
+	}
+	defer file.Close()
+
+	set := make(Set)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		set[word] = true
+	}
+	cache.Store(language, set)
+	return set
+}
+
+func (s Set) Contains(word string) bool {
+	return s[strings.ToLower(word)]
+}
@@ -0,0 +1,12 @@
+package stopwords
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	if !Get("").Contains("The") || !Get("en").Contains("the") {
+		t.Error("English stopwords are missing")
+	}
+	if set := Get("xx"); len(set) != 0 {
+		t.Errorf("unknown language has %d stopwords, want none", len(set))
+	}
+}
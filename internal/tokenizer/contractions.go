@@ -0,0 +1,103 @@
+package tokenizer
+
+import "strings"
+
+// English contractions that can't be expanded by their suffix alone
+var irregular = map[string][]string{
+	"won't":   {"will", "not"},
+	"can't":   {"can", "not"},
+	"shan't":  {"shall", "not"},
+	"ain't":   {"is", "not"},
+	"let's":   {"let", "us"},
+	"it's":    {"it", "is"},
+	"he's":    {"he", "is"},
+	"she's":   {"she", "is"},
+	"that's":  {"that", "is"},
+	"there's": {"there", "is"},
+	"here's":  {"here", "is"},
+	"what's":  {"what", "is"},
+	"who's":   {"who", "is"},
+	"where's": {"where", "is"},
+	"how's":   {"how", "is"},
+	"y'all":   {"you", "all"},
+}
+
+// English suffixes, checked in order, "n't" before the shorter ones
+var suffixes = []struct {
+	suffix string
+	word   string
+}{
+	{"n't", "not"},
+	{"'re", "are"},
+	{"'ve", "have"},
+	{"'ll", "will"},
+	{"'m", "am"},
+	{"'d", "would"},
+	// possessive, the owner is the word that matters
+	{"'s", ""},
+}
+
+// French articles and pronouns that lose their vowel before another vowel,
+// as in "l'homme" or "qu'il"
+var elisions = map[string]string{
+	"l":  "le",
+	"d":  "de",
+	"j":  "je",
+	"m":  "me",
+	"t":  "te",
+	"s":  "se",
+	"c":  "ce",
+	"n":  "ne",
+	"qu": "que",
+}
+
+func expand(word string, start, end int, rule ContractionRule, language string) []Token {
+	token := func(w string) Token {
+		return Token{Word: w, Start: start, End: end}
+	}
+	if !strings.Contains(word, "'") {
+		return []Token{token(word)}
+	}
+	switch rule {
+	case ContractionKeep:
+		return []Token{token(word)}
+	case ContractionStrip:
+		return []Token{token(strings.ReplaceAll(word, "'", ""))}
+	}
+
+	switch language {
+	case "", "en":
+	case "fr", "it":
+		// elided words are words of their own
+		var tokens []Token
+		for _, part := range strings.Split(word, "'") {
+			if full, ok := elisions[part]; ok && language == "fr" {
+				part = full
+			}
+			tokens = append(tokens, token(part))
+		}
+		return tokens
+	default:
+		return []Token{token(word)}
+	}
+
+	if words, ok := irregular[word]; ok {
+		tokens := make([]Token, len(words))
+		for i := range words {
+			tokens[i] = token(words[i])
+		}
+		return tokens
+	}
+	for _, s := range suffixes {
+		base, ok := strings.CutSuffix(word, s.suffix)
+		if !ok || base == "" || strings.Contains(base, "'") {
+			continue
+		}
+		if s.word == "" {
+			return []Token{token(base)}
+		}
+		return []Token{token(base), token(s.word)}
+	}
+	// o'clock, rock'n'roll
+	return []Token{token(word)}
+}
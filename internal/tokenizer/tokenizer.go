@@ -0,0 +1,150 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// HyphenRule decides what happens to words like "well-being".
+type HyphenRule string
+
+const (
+	// "well", "being"
+	HyphenSplit HyphenRule = "split"
+	// "wellbeing"
+	HyphenJoin HyphenRule = "join"
+	// "well-being"
+	HyphenKeep HyphenRule = "keep"
+)
+
+// ContractionRule decides what happens to words like "don't" and "one's".
+type ContractionRule string
+
+const (
+	// "do", "not" and "one"
+	ContractionExpand ContractionRule = "expand"
+	// "don't" and "one's"
+	ContractionKeep ContractionRule = "keep"
+	// "dont" and "ones", the way words were sanitized before the tokenizer
+	ContractionStrip ContractionRule = "strip"
+)
+
+// Options is part of a challenge, so its quote and every passage played
+// against it are split the same way. The zero value splits hyphenated words
+// and expands contractions.
+type Options struct {
+	Hyphens      HyphenRule      `json:"hyphens,omitempty"`
+	Contractions ContractionRule `json:"contractions,omitempty"`
+	// filled in from the challenge, contractions are expanded by the rules
+	// of this language
+	Language string `json:"-"`
+}
+
+// Token is a normalized lowercase word and the rune offsets of the text it
+// came from, Start inclusive and End exclusive. Tokens expanded from one
+// contraction share the same span.
+type Token struct {
+	Word  string `json:"word"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Words drops the spans.
+func Words(tokens []Token) []string {
+	words := make([]string, len(tokens))
+	for i := range tokens {
+		words[i] = tokens[i].Word
+	}
+	return words
+}
+
+// Tokenize splits text into words. Text is NFKC normalized and lowercased,
+// apostrophes and hyphens are only part of a word between two letters and
+// every other non-letter separates words.
+func Tokenize(text string, opts Options) []Token {
+	var tokens []Token
+	var word strings.Builder
+	var pending rune
+	start, end := -1, 0
+
+	flush := func() {
+		if word.Len() > 0 {
+			// compose letters and combining marks that came as separate runes
+			composed := norm.NFKC.String(word.String())
+			tokens = append(tokens, expand(composed, start, end, opts.Contractions, opts.Language)...)
+		}
+		word.Reset()
+		pending = 0
+		start = -1
+	}
+
+	pos := 0
+	for _, original := range text {
+		normalized := normalize(original)
+		r := []rune(normalized)[0]
+		switch {
+		case r == '\u00ad':
+			// soft hyphen, invisible and only there to allow line breaks
+		case isApostrophe(r) || isHyphen(r):
+			if word.Len() == 0 || pending != 0 {
+				flush()
+			} else if isApostrophe(r) {
+				pending = '\''
+			} else {
+				pending = '-'
+			}
+		case isWordRune(r):
+			if pending == '\'' || (pending == '-' && opts.Hyphens == HyphenKeep) {
+				word.WriteRune(pending)
+			} else if pending == '-' && opts.Hyphens != HyphenJoin {
+				flush()
+			}
+			pending = 0
+			if start < 0 {
+				start = pos
+			}
+			word.WriteString(normalized)
+			end = pos + 1
+		default:
+			flush()
+		}
+		pos++
+	}
+	flush()
+	return tokens
+}
+
+// normalize returns the lowercased NFKC form of a single rune, so fullwidth
+// letters and ligatures are read like their plain forms.
+func normalize(r rune) string {
+	if r < unicode.MaxASCII {
+		return string(unicode.ToLower(r))
+	}
+	normalized := strings.ToLower(norm.NFKC.String(string(r)))
+	if normalized == "" {
+		return string(r)
+	}
+	return normalized
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+func isApostrophe(r rune) bool {
+	switch r {
+	case '\'', '\u2018', '\u2019', '\u02bc':
+		return true
+	}
+	return false
+}
+
+func isHyphen(r rune) bool {
+	switch r {
+	case '-', '\u2010', '\u2011':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package tokenizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	keep := Options{Hyphens: HyphenKeep, Contractions: ContractionKeep}
+	strip := Options{Hyphens: HyphenJoin, Contractions: ContractionStrip}
+
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want []string
+	}{
+		{"plain", "The future belongs to those", Options{}, []string{"the", "future", "belongs", "to", "those"}},
+		{"punctuation", "light. Hope, (and) \"faith\"!", Options{}, []string{"light", "hope", "and", "faith"}},
+		{"contraction expanded", "Don't judge", Options{}, []string{"do", "not", "judge"}},
+		{"curly apostrophe", "Don’t judge", Options{}, []string{"do", "not", "judge"}},
+		{"modifier apostrophe", "don\u02bct", Options{}, []string{"do", "not"}},
+		{"irregular", "won't can't let's", Options{}, []string{"will", "not", "can", "not", "let", "us"}},
+		{"suffixes", "we're you've they'll I'm she'd", Options{}, []string{"we", "are", "you", "have", "they", "will", "i", "am", "she", "would"}},
+		{"possessive", "one’s aspirations", Options{}, []string{"one", "aspirations"}},
+		{"plural possessive", "the dreamers' hopes", Options{}, []string{"the", "dreamers", "hopes"}},
+		{"quoted word", "'hope' and ‘light’", Options{}, []string{"hope", "and", "light"}},
+		{"unknown apostrophe", "five o'clock", Options{}, []string{"five", "o'clock"}},
+		{"contraction kept", "Don't one's", keep, []string{"don't", "one's"}},
+		{"contraction stripped", "Don't one's", strip, []string{"dont", "ones"}},
+		{"hyphen split", "well-being", Options{}, []string{"well", "being"}},
+		{"hyphen joined", "well-being", strip, []string{"wellbeing"}},
+		{"hyphen kept", "well\u2010being", keep, []string{"well-being"}},
+		{"dangling hyphen", "self- and well-", keep, []string{"self", "and", "well"}},
+		{"em dash separates", "hope—and light – always", Options{}, []string{"hope", "and", "light", "always"}},
+		{"soft hyphen", "won\u00adder", Options{}, []string{"wonder"}},
+		{"numbers", "up to 2,000 eggs", Options{}, []string{"up", "to", "2", "000", "eggs"}},
+		{"fullwidth", "ＨＯＰＥ", Options{}, []string{"hope"}},
+		{"ligature", "ﬁnd ﬂight", Options{}, []string{"find", "flight"}},
+		{"combining accent", "cafe\u0301 caf\u00e9", Options{}, []string{"caf\u00e9", "caf\u00e9"}},
+		{"non-latin", "Соня и Ёж", Options{}, []string{"соня", "и", "ёж"}},
+		{"nbsp", "seeds\u00a0that", Options{}, []string{"seeds", "that"}},
+		{"empty", " — ... ", Options{}, []string{}},
+		{"french elision", "L’homme qu'il aime", Options{Language: "fr"}, []string{"le", "homme", "que", "il", "aime"}},
+		{"italian elision", "dell'anima", Options{Language: "it"}, []string{"dell", "anima"}},
+		{"spanish punctuation", "¿Qué es la vida? ¡Un frenesí!", Options{Language: "es"}, []string{"qué", "es", "la", "vida", "un", "frenesí"}},
+		{"english rules only for english", "don't", Options{Language: "de"}, []string{"don't"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(Tokenize(tt.text, tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenSpans(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{"“Hope,” she said", []Token{{"hope", 1, 5}, {"she", 8, 11}, {"said", 12, 16}}},
+		{"Don’t", []Token{{"do", 0, 5}, {"not", 0, 5}}},
+		{"well-being", []Token{{"well", 0, 4}, {"being", 5, 10}}},
+		{"ﬁnd it", []Token{{"find", 0, 3}, {"it", 4, 6}}},
+	}
+	for _, tt := range tests {
+		got := Tokenize(tt.text, Options{})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,7 @@ from fastapi import FastAPI, HTTPException
 from pydantic import BaseModel
 from typing import List
 import nltk
-from nltk.stem import PorterStemmer
+from nltk.stem import PorterStemmer, SnowballStemmer
 
 
 try:
@@ -15,6 +15,24 @@ app = FastAPI(title="Word Stemming Microservice")
 # Initialize stemmer
 stemmer = PorterStemmer()
 
+# Snowball stemmers for the other challenge languages, by ISO 639-1 code
+SNOWBALL_LANGUAGES = {
+    "es": "spanish",
+    "fr": "french",
+    "de": "german",
+    "it": "italian",
+    "pt": "portuguese",
+    "nl": "dutch",
+}
+snowball_stemmers = {}
+
+def get_stemmer(language: str):
+    if language not in SNOWBALL_LANGUAGES:
+        return stemmer
+    if language not in snowball_stemmers:
+        snowball_stemmers[language] = SnowballStemmer(SNOWBALL_LANGUAGES[language])
+    return snowball_stemmers[language]
+
 class StemRequest(BaseModel):
     content: List[str]
     challenge_words: List[str]
@@ -45,6 +63,21 @@ async def find_common_words(request: StemRequest):
     except Exception as e:
         raise HTTPException(status_code=500, detail=f"An error occurred: {str(e)}")
 
+class StemWordsRequest(BaseModel):
+    words: List[str]
+    language: str = "en"
+
+class StemWordsResponse(BaseModel):
+    stems: List[str]
+
+@app.post("/stem", response_model=StemWordsResponse)
+async def stem_words(request: StemWordsRequest):
+    try:
+        language_stemmer = get_stemmer(request.language)
+        return StemWordsResponse(stems=[language_stemmer.stem(word.lower()) for word in request.words])
+    except Exception as e:
+        raise HTTPException(status_code=500, detail=f"An error occurred: {str(e)}")
+
 @app.get("/health")
 async def health_check():
     return {"status": "healthy"}
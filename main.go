@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 
+	database "github.com/kirtansoni/words-weave/internal/database"
 	f "github.com/kirtansoni/words-weave/internal/frontend"
 	g "github.com/kirtansoni/words-weave/internal/game"
 )
@@ -14,6 +15,9 @@ import (
 var (
 	addr    = flag.String("addr", ":8080", "Port of the server")
 	logfile = flag.String("logfile", "logs/app.logs", "set Logfile")
+	dbfile  = flag.String("db", "./challenges.db", "SQLite database file")
+	// off when migrations are run with the migrate command
+	automigrate = flag.Bool("automigrate", true, "apply pending database migrations at startup")
 )
 
 func InitalizeLogging(filename string) *os.File {
@@ -27,16 +31,72 @@ func InitalizeLogging(filename string) *os.File {
 
 func main() {
 	flag.Parse()
+	switch flag.Arg(0) {
+	case "migrate":
+		os.Exit(migrateCommand(flag.Args()[1:]))
+	case "simulate":
+		os.Exit(simulateCommand(flag.Args()[1:]))
+	case "pack":
+		os.Exit(packCommand(flag.Args()[1:]))
+	case "generate":
+		os.Exit(generateCommand(flag.Args()[1:]))
+	case "import":
+		os.Exit(importCommand(flag.Args()[1:]))
+	case "hash-password":
+		os.Exit(hashPasswordCommand())
+	}
 	file := InitalizeLogging(*logfile)
 	defer file.Close()
 	ctx := context.Background()
 	game := g.GetGame()
+
+	db, err := database.Open(*dbfile, *automigrate)
+	if err != nil {
+		log.Fatal("Database failed:", err)
+	}
+	defer db.Close()
+	if !*automigrate {
+		// the schema is migrated on command, refuse to run on an old one
+		if err := checkMigrated(db); err != nil {
+			log.Fatal("Database failed:", err)
+		}
+	}
+	game.SetDB(db)
+	// the admin API takes the token, or basic auth with a password hashed
+	// by the hash-password command
+	game.AdminToken = os.Getenv("WORDS_WEAVE_ADMIN_TOKEN")
+	game.AdminUser = os.Getenv("WORDS_WEAVE_ADMIN_USER")
+	game.AdminPassword = os.Getenv("WORDS_WEAVE_ADMIN_PASSWORD_HASH")
 	game.Init(ctx)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /", f.GetReactSPA().ServeHTTP)
 	mux.HandleFunc("GET /game", game.Getgamestate)
 	mux.HandleFunc("POST /game", game.Postgamestate)
+	mux.HandleFunc("POST /game/hint", game.Posthint)
+	mux.HandleFunc("POST /game/rewind", game.Postrewind)
+	mux.HandleFunc("GET /game/share", game.Getshare)
+	mux.HandleFunc("GET /r/{id}", game.Getsharedresult)
+	mux.HandleFunc("GET /r/{id}/card.png", game.Getsharecard)
+	mux.HandleFunc("GET /stats", game.Getstats)
+	mux.HandleFunc("GET /archive", game.Getarchive)
+	mux.HandleFunc("GET /admin/reports/difficulty", game.Admin(game.Getdifficultyreport))
+	mux.HandleFunc("POST /admin/packs/check", game.Admin(game.Postpackcheck))
+	mux.HandleFunc("GET /admin/drafts", game.Admin(game.Getdrafts))
+	mux.HandleFunc("POST /admin/drafts/{id}/approve", game.Admin(game.Postapprovedraft))
+	mux.HandleFunc("GET /admin/challenges", game.Admin(game.Getquotes))
+	mux.HandleFunc("POST /admin/challenges", game.Admin(game.Postquote))
+	mux.HandleFunc("PATCH /admin/challenges/{id}", game.Admin(game.Patchquote))
+	mux.HandleFunc("POST /admin/challenges/{id}/schedule", game.Admin(game.Postschedulequote))
+	mux.HandleFunc("POST /admin/challenges/{id}/retire", game.Admin(game.Postretirequote))
+	mux.HandleFunc("GET /admin/sessions", game.Admin(game.Getsessions))
+	mux.HandleFunc("GET /admin/sessions/{key}", game.Admin(game.Getsession))
+	mux.HandleFunc("DELETE /admin/sessions/{key}", game.Admin(game.Deletesession))
+	mux.HandleFunc("GET /admin/live", game.Admin(game.Getlive))
+	mux.HandleFunc("POST /admin/rollover", game.Admin(game.Postrollover))
+	mux.HandleFunc("GET /leaderboard", game.Getleaderboard)
+	mux.HandleFunc("POST /leaderboard/optin", game.Postoptin)
+	mux.HandleFunc("DELETE /leaderboard/optin", game.Deleteoptin)
 
 	// starting server
 	log.Println("Starting Server at " + *addr)
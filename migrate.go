@@ -0,0 +1,72 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	database "github.com/kirtansoni/words-weave/internal/database"
+)
+
+// migrateCommand runs `migrate` to apply pending migrations or
+// `migrate status` to list them, it returns the exit code.
+func migrateCommand(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words-weave [-db file] migrate [status]")
+	}
+	fs.Parse(args)
+
+	db, err := database.Open(*dbfile, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Database failed:", err)
+		return 1
+	}
+	defer db.Close()
+
+	switch fs.Arg(0) {
+	case "":
+		done, err := database.Migrate(db)
+		for _, migration := range done {
+			fmt.Println("applied", migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Migration failed:", err)
+			return 1
+		}
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "status":
+		statuses, err := database.Status(db)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Migration status failed:", err)
+			return 1
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if !s.AppliedAt.IsZero() {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-40s %s\n", s.Name, applied)
+		}
+	default:
+		fs.Usage()
+		return 2
+	}
+	return 0
+}
+
+func checkMigrated(db *sql.DB) error {
+	statuses, err := database.Status(db)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		if s.AppliedAt.IsZero() {
+			return fmt.Errorf("migration %s is pending, run the migrate command", s.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kirtansoni/words-weave/internal/associations"
+	database "github.com/kirtansoni/words-weave/internal/database"
+	g "github.com/kirtansoni/words-weave/internal/game"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	m "github.com/kirtansoni/words-weave/internal/models"
+	"github.com/kirtansoni/words-weave/internal/packs"
+	"github.com/kirtansoni/words-weave/internal/quality"
+)
+
+// packCommand runs `pack pack.json` to check the starting content of a
+// pack, with -date the pack is scheduled for that day unless a challenge
+// failed. It returns the exit code.
+func packCommand(args []string) int {
+	fs := flag.NewFlagSet("pack", flag.ExitOnError)
+	date := fs.String("date", "", "schedule the pack for this day, YYYY-MM-DD")
+	force := fs.Bool("force", false, "schedule even if a challenge failed the checks")
+	stemmer := fs.String("stemmer", "remote", "local suffix stripping or the remote microservice")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words-weave [-db file] pack [flags] pack.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *date != "" {
+		if _, err := time.Parse(database.DAY_FORMAT, *date); err != nil {
+			fmt.Fprintln(os.Stderr, "Days are YYYY-MM-DD")
+			return 2
+		}
+	}
+	validator := quality.Validator{Stemmer: matcher.GetStemmer()}
+	switch *stemmer {
+	case "local":
+		validator.Stemmer = matcher.LocalStemmer{}
+	case "remote":
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	pack, err := packs.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Loading pack failed:", err)
+		return 1
+	}
+	db, err := database.Open(*dbfile, *automigrate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Database failed:", err)
+		return 1
+	}
+	defer db.Close()
+	validator.Graph = associations.New(db)
+
+	reports, ok, err := pack.Check(validator)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Checking pack failed:", err)
+		return 1
+	}
+	for i, report := range reports {
+		fmt.Printf("%3d  %-4s  %q\n", i, report.Status, report.Quote)
+		for _, check := range report.Checks {
+			if check.Status != quality.PASS {
+				fmt.Printf("       %-4s  %s: %s\n", check.Status, check.Name, check.Detail)
+			}
+		}
+	}
+	if *date == "" {
+		if !ok {
+			return 1
+		}
+		return 0
+	}
+	if !ok && !*force {
+		fmt.Fprintln(os.Stderr, "Not scheduled, fix the failed challenges or use -force")
+		return 1
+	}
+	if err := checkSchedule(*date, database.Today(time.Now()), pack.Challenges()); err != nil {
+		fmt.Fprintln(os.Stderr, "Scheduling failed:", err)
+		return 1
+	}
+	if err := database.SaveChallenges(db, *date, pack.Challenges()); err != nil {
+		fmt.Fprintln(os.Stderr, "Scheduling failed:", err)
+		return 1
+	}
+	fmt.Printf("scheduled %d challenges on %s\n", len(reports), *date)
+	return 0
+}
+
+// checkSchedule holds a pack to the rules of scheduling a quote: days before
+// today are over and a day has as many challenges per language as the game
+// plays.
+func checkSchedule(date, today string, challenges []m.Challenge) error {
+	if date < today {
+		return fmt.Errorf("%w: %s", database.ErrPastDay, date)
+	}
+	counts := make(map[string]int)
+	for _, challenge := range challenges {
+		language := challenge.GetLanguage()
+		if counts[language]++; counts[language] > g.MAXCHALLENGES+1 {
+			return fmt.Errorf("%w: %s %s", database.ErrDayFull, date, language)
+		}
+	}
+	return nil
+}
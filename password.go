@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kirtansoni/words-weave/internal/password"
+)
+
+// hashPasswordCommand runs `hash-password`, it reads a password from stdin
+// and prints the hash to set as WORDS_WEAVE_ADMIN_PASSWORD_HASH. It returns
+// the exit code.
+func hashPasswordCommand() int {
+	fmt.Fprint(os.Stderr, "password: ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		fmt.Fprintln(os.Stderr, "No password given")
+		return 1
+	}
+	hash, err := password.Hash(line)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Hashing failed:", err)
+		return 1
+	}
+	fmt.Println(hash)
+	return 0
+}
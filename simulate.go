@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	g "github.com/kirtansoni/words-weave/internal/game"
+	l "github.com/kirtansoni/words-weave/internal/llm"
+	"github.com/kirtansoni/words-weave/internal/matcher"
+	"github.com/kirtansoni/words-weave/internal/packs"
+	"github.com/kirtansoni/words-weave/internal/simulate"
+)
+
+// simulateCommand runs `simulate pack.json`, a bot plays every challenge
+// of the pack and prints how often it solved them, it returns the exit
+// code.
+func simulateCommand(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	runs := fs.Int("runs", 20, "games played per challenge")
+	policy := fs.String("policy", simulate.GREEDY, "how the bot picks words, greedy or beam")
+	beam := fs.Int("beam", 3, "passages the beam policy keeps")
+	mode := fs.String("mode", "", "game mode played")
+	provider := fs.String("provider", "fake", "passages from the offline fake or openai")
+	stemmer := fs.String("stemmer", "local", "local suffix stripping or the remote microservice")
+	seed := fs.Uint64("seed", 1, "seed of the bot and the fake")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words-weave simulate [flags] pack.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := simulate.Config{Runs: *runs, Policy: *policy, Beam: *beam, Mode: *mode, Seed: *seed}
+	switch *provider {
+	case "fake":
+		cfg.LLM = l.Fake{Seed: *seed}
+	case "openai":
+		cfg.LLM = l.GetProvider()
+	default:
+		fs.Usage()
+		return 2
+	}
+	switch *stemmer {
+	case "local":
+		cfg.Stemmer = matcher.LocalStemmer{}
+	case "remote":
+		cfg.Stemmer = matcher.GetStemmer()
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	pack, err := packs.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Loading pack failed:", err)
+		return 1
+	}
+	limit := fmt.Sprintf("up to %d attempts", g.GetMode(*mode).MaxAttempts)
+	if g.GetMode(*mode).MaxAttempts == 0 {
+		limit = fmt.Sprintf("giving up after %d attempts", simulate.MAX_STEPS)
+	}
+	fmt.Printf("%s: %d runs each, %s policy, %s\n", pack.Name, *runs, *policy, limit)
+	for i, challenge := range pack.Challenges() {
+		report, err := simulate.Run(challenge, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Simulation failed:", err)
+			return 1
+		}
+		difficulty := report.Difficulty
+		if difficulty == "" {
+			difficulty = "-"
+		}
+		fmt.Printf("%3d  %-6s  solved %5.1f%%  attempts %5.1f  %q\n",
+			i, difficulty, report.SolveRate*100, report.ExpectedAttempts, report.Quote)
+	}
+	return 0
+}